@@ -0,0 +1,215 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// validateBranchName applies the rules of `git check-ref-format --branch`
+// so invalid names are rejected before git is run.
+func validateBranchName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("branch name is empty")
+	case name == "@" || name == "HEAD":
+		return fmt.Errorf("%q is not a valid branch name", name)
+	case strings.HasPrefix(name, "-"):
+		return errors.New("cannot start with '-'")
+	case strings.HasPrefix(name, "/") || strings.HasSuffix(name, "/"):
+		return errors.New("cannot start or end with '/'")
+	case strings.Contains(name, "//"):
+		return errors.New("cannot contain '//'")
+	case strings.Contains(name, ".."):
+		return errors.New("cannot contain '..'")
+	case strings.Contains(name, "@{"):
+		return errors.New("cannot contain '@{'")
+	case strings.HasSuffix(name, "."):
+		return errors.New("cannot end with '.'")
+	}
+
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f {
+			return errors.New("cannot contain control characters")
+		}
+		if strings.ContainsRune(" ~^:?*[\\", r) {
+			return fmt.Errorf("cannot contain %q", r)
+		}
+	}
+
+	for _, component := range strings.Split(name, "/") {
+		if strings.HasPrefix(component, ".") {
+			return errors.New("path components cannot start with '.'")
+		}
+		if strings.HasSuffix(component, ".lock") {
+			return errors.New("path components cannot end with '.lock'")
+		}
+	}
+	return nil
+}
+
+// checkNewBranch validates name against git's rules and the repository's
+// naming rules, and makes sure neither the branch nor its worktree
+// directory already exists. branches are the known branches.
+func checkNewBranch(repo, name string, branches []Branch, rules NamingRules) error {
+	if err := checkBranchName(name, branches, rules); err != nil {
+		return err
+	}
+	return loadWorktreeDirs(repo).check(name)
+}
+
+// checkBranchName is the part of checkNewBranch that needs neither git nor
+// the disk.
+func checkBranchName(name string, branches []Branch, rules NamingRules) error {
+	if err := validateBranchName(name); err != nil {
+		return err
+	}
+	if err := rules.check(name); err != nil {
+		return err
+	}
+
+	for _, branch := range branches {
+		if branch.Type == "local" && branch.Name == name {
+			return fmt.Errorf("branch '%s' already exists", name)
+		}
+	}
+	return nil
+}
+
+// worktreeDirs is a snapshot of the directory new worktrees are created
+// in, so the new branch input can check names as they are typed without
+// running git or reading the disk on every keystroke.
+type worktreeDirs struct {
+	repoRoot string
+	taken    map[string]bool // names of the entries next to the repository
+	err      error
+}
+
+func loadWorktreeDirs(repo string) worktreeDirs {
+	repoRoot, err := getRepoRoot(repo)
+	if err != nil {
+		return worktreeDirs{err: err}
+	}
+	dirs := worktreeDirs{repoRoot: repoRoot, taken: make(map[string]bool)}
+	entries, err := os.ReadDir(filepath.Dir(repoRoot))
+	if err != nil {
+		return worktreeDirs{err: err}
+	}
+	for _, entry := range entries {
+		dirs.taken[entry.Name()] = true
+	}
+	return dirs
+}
+
+// check fails when the worktree directory for branchName already exists.
+func (d worktreeDirs) check(branchName string) error {
+	if d.err != nil {
+		return d.err
+	}
+	path := worktreePathIn(d.repoRoot, branchName)
+	if d.taken[filepath.Base(path)] {
+		return fmt.Errorf("%s already exists", path)
+	}
+	return nil
+}
+
+// checkNewBranch checks name like the function of the same name, but
+// against the branches and directories already in memory.
+func (m model) checkNewBranch(name string) error {
+	if err := checkBranchName(name, m.allBranches, m.newBranchRules); err != nil {
+		return err
+	}
+	return m.newBranchDirs.check(name)
+}
+
+// branchNameWarning points out characters git accepts but that are
+// outside the portable set, since shells and other tools trip over them.
+func branchNameWarning(name string) string {
+	for _, r := range name {
+		if !isValidBranchChar(string(r)) {
+			return fmt.Sprintf("%q is allowed by git but may need quoting", r)
+		}
+	}
+	return ""
+}
+
+// confirmNewBranch creates the typed branch if it is valid, or, when the
+// input is an issue key for the configured tracker, replaces it with a
+// proposed name so it can be reviewed and confirmed.
+func (m model) confirmNewBranch() (model, tea.Cmd) {
+	input, key := m.parseNewBranchInput()
+	if input == "" {
+		return m, nil
+	}
+	if key != "" {
+		m.statusMessage = fmt.Sprintf("Looking up %s...", key)
+		return m, lookupIssueBranchCmd(m.tracker, m.config.Tracker, key)
+	}
+	if err := m.checkNewBranch(input); err != nil {
+		m.newBranchError = err.Error()
+		return m, nil
+	}
+	if m.promoteSource != "" {
+		opts, err := m.worktreeOptions(m.newSparse)
+		if err != nil {
+			m.statusMessage = fmt.Sprintf("%s %v", markers.Error, err)
+			return m, clearStatusAfterDelay()
+		}
+		m.creatingNewBranch = true
+		m.creatingNewBranchName = input
+		return m, promoteChangesCmd(m.promoteSource, input, opts)
+	}
+	return m, createNewBranchWorktreeCmd(input)
+}
+
+// parseNewBranchInput returns the branch name typed into the new branch
+// input, and the issue key to look up when it is one. A leading
+// issueKeyEscape after the prefix is dropped and keeps the name as is, and
+// so does the key the current proposal came from, since a template of
+// just "{key}" proposes the key itself.
+func (m model) parseNewBranchInput() (name, key string) {
+	name = m.newBranchInput.Value()
+	prefix := m.currentPrefix()
+	rest := strings.TrimPrefix(name, prefix)
+	if escaped, ok := strings.CutPrefix(rest, issueKeyEscape); ok {
+		return name[:len(name)-len(rest)] + escaped, ""
+	}
+	if m.tracker == nil {
+		return name, ""
+	}
+	if key, ok := m.config.Tracker.issueKey(rest); ok && key != m.newBranchIssue {
+		return name, key
+	}
+	return name, ""
+}
+
+// withPrefix puts the selected prefix in front of a proposed branch name
+// unless the name already starts with an allowed prefix.
+func (m model) withPrefix(name string) string {
+	for _, prefix := range m.newBranchRules.Prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return name
+		}
+	}
+	return m.currentPrefix() + name
+}
+
+// updateNewBranchError revalidates the new branch input after each edit.
+func (m *model) updateNewBranchError() {
+	name, key := m.parseNewBranchInput()
+	m.newBranchError = ""
+	m.newBranchWarning = ""
+	if name == "" || key != "" {
+		// Issue keys are replaced with a proposed name before creation
+		return
+	}
+	if err := m.checkNewBranch(name); err != nil {
+		m.newBranchError = err.Error()
+		return
+	}
+	m.newBranchWarning = branchNameWarning(name)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateBranchName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"feature/login", true},
+		{"fix-123_v2.1", true},
+		{"user@host", true},
+		{"", false},
+		{"@", false},
+		{"HEAD", false},
+		{"-leading-dash", false},
+		{"/leading", false},
+		{"trailing/", false},
+		{"double//slash", false},
+		{"dots..here", false},
+		{"reflog@{1}", false},
+		{"ends.", false},
+		{"feature/.hidden", false},
+		{"feature.lock", false},
+		{"feature.lock/child", false},
+		{"has space", false},
+		{"tilde~1", false},
+		{"caret^", false},
+		{"colon:x", false},
+		{"glob*", false},
+		{"question?", false},
+		{"bracket[", false},
+		{"back\\slash", false},
+		{"bell\a", false},
+	}
+
+	_, gitErr := exec.LookPath("git")
+	for _, test := range tests {
+		err := validateBranchName(test.name)
+		if (err == nil) != test.valid {
+			t.Errorf("validateBranchName(%q) = %v, expected valid=%v", test.name, err, test.valid)
+		}
+		// --branch expands "@" to the current branch, so git can't judge it
+		if gitErr != nil || test.name == "" || test.name == "@" {
+			continue
+		}
+		// Stay in sync with git's own rules
+		gitValid := exec.Command("git", "check-ref-format", "--branch", test.name).Run() == nil
+		if gitValid != test.valid {
+			t.Errorf("git check-ref-format --branch %q: valid=%v, expected %v", test.name, gitValid, test.valid)
+		}
+	}
+}
+
+func TestCheckNewBranch(t *testing.T) {
+	repo := newTestRepo(t)
+	t.Chdir(repo)
+
+	branches := []Branch{{Name: "taken", Type: "local"}, {Name: "origin/remote-only", Type: "remote"}}
+	if err := checkNewBranch(".", "taken", branches, NamingRules{}); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected existing branch error, got %v", err)
+	}
+	if err := checkNewBranch(".", "remote-only", branches, NamingRules{}); err != nil {
+		t.Errorf("Expected a new local branch to be allowed, got %v", err)
+	}
+
+	path, err := worktreePathFor(".", "feature/used")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(path, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkNewBranch(".", "feature/used", nil, NamingRules{}); err == nil || !strings.Contains(err.Error(), filepath.Base(path)) {
+		t.Errorf("Expected existing path error, got %v", err)
+	}
+
+	// The input checks against what was read when it opened, without git
+	m := initialModel()
+	m.view = "branches"
+	m.startNewBranch()
+	t.Chdir(t.TempDir())
+	if err := m.checkNewBranch("feature/used"); err == nil || !strings.Contains(err.Error(), filepath.Base(path)) {
+		t.Errorf("Expected existing path error from the snapshot, got %v", err)
+	}
+	if err := m.checkNewBranch("feature/free"); err != nil {
+		t.Errorf("Expected a free name to pass, got %v", err)
+	}
+}
+
+func TestNewBranchInputShowsErrors(t *testing.T) {
+	m := initialModel()
+	m.view = "branches"
+	m.startNewBranch()
+
+	m.newBranchInput.SetValue("bad..name")
+	m.updateNewBranchError()
+	if m.newBranchError == "" {
+		t.Fatal("Expected an error for an invalid name")
+	}
+	if view := m.View(); !strings.Contains(view, "cannot contain '..'") {
+		t.Errorf("Expected the error in the view, got:\n%s", view)
+	}
+
+	m, cmd := m.confirmNewBranch()
+	if cmd != nil {
+		t.Error("Expected an invalid name not to be created")
+	}
+
+	m.newBranchInput.SetValue("odd#name")
+	m.updateNewBranchError()
+	if m.newBranchError != "" || m.newBranchWarning == "" {
+		t.Errorf("Expected only a warning, got error %q warning %q", m.newBranchError, m.newBranchWarning)
+	}
+}
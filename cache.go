@@ -0,0 +1,87 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// backgroundSlots bounds how many slow commands, such as walking a
+// worktree on disk or running git status in it, run at once, so a long
+// worktree list doesn't start them all in parallel.
+var backgroundSlots = make(chan struct{}, 4)
+
+// asyncCache holds values that are slow to compute, keyed by e.g. a
+// worktree path, and loads them in the background. A key that is loading
+// is never loaded a second time, and its previous value, if any, stays
+// until the new one is set. The zero value is an empty cache that keeps
+// values until they are forgotten.
+type asyncCache[V any] struct {
+	maxAge  time.Duration // values older than this are loaded again, if set
+	entries map[string]cacheEntry[V]
+}
+
+type cacheEntry[V any] struct {
+	value    V
+	loaded   bool
+	loading  bool
+	loadedAt time.Time
+}
+
+// load returns a command that runs fn to compute the value of key, unless
+// it is loading or cached and not older than maxAge. fn returns the
+// message that delivers the value to Update, which stores it with set.
+func (c *asyncCache[V]) load(key string, fn func() tea.Msg) tea.Cmd {
+	if entry, ok := c.entries[key]; ok {
+		if entry.loading || c.maxAge == 0 || time.Since(entry.loadedAt) < c.maxAge {
+			return nil
+		}
+	}
+	return c.start(key, fn)
+}
+
+// reload is load for values that may have changed since: it only skips
+// keys that are loading already.
+func (c *asyncCache[V]) reload(key string, fn func() tea.Msg) tea.Cmd {
+	if c.entries[key].loading {
+		return nil
+	}
+	return c.start(key, fn)
+}
+
+func (c *asyncCache[V]) start(key string, fn func() tea.Msg) tea.Cmd {
+	if c.entries == nil {
+		c.entries = make(map[string]cacheEntry[V])
+	}
+	entry := c.entries[key]
+	entry.loading = true
+	c.entries[key] = entry
+	return func() tea.Msg {
+		backgroundSlots <- struct{}{}
+		defer func() { <-backgroundSlots }()
+		return fn()
+	}
+}
+
+func (c *asyncCache[V]) set(key string, value V) {
+	if c.entries == nil {
+		c.entries = make(map[string]cacheEntry[V])
+	}
+	c.entries[key] = cacheEntry[V]{value: value, loaded: true, loadedAt: time.Now()}
+}
+
+// get returns the value of key, and whether one has been loaded.
+func (c *asyncCache[V]) get(key string) (V, bool) {
+	entry := c.entries[key]
+	return entry.value, entry.loaded
+}
+
+// forget drops key, so the next load computes it again.
+func (c *asyncCache[V]) forget(key string) {
+	delete(c.entries, key)
+}
+
+// clear forgets every key.
+func (c *asyncCache[V]) clear() {
+	c.entries = nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestAsyncCache(t *testing.T) {
+	type loadedMsg struct{ value int }
+	var cache asyncCache[int]
+	fn := func() tea.Msg { return loadedMsg{value: 42} }
+
+	cmd := cache.load("a", fn)
+	if cmd == nil {
+		t.Fatal("Expected a missing key to load")
+	}
+	if cache.load("a", fn) != nil || cache.reload("a", fn) != nil {
+		t.Error("Expected a loading key not to load again")
+	}
+	if _, ok := cache.get("a"); ok {
+		t.Error("Expected no value before the load finishes")
+	}
+
+	msg := cmd().(loadedMsg)
+	cache.set("a", msg.value)
+	if value, ok := cache.get("a"); !ok || value != 42 {
+		t.Errorf("Expected 42, got %d (%v)", value, ok)
+	}
+	if cache.load("a", fn) != nil {
+		t.Error("Expected a cached key not to load again")
+	}
+	if cache.reload("a", fn) == nil {
+		t.Error("Expected reload to load a cached key")
+	}
+	if value, _ := cache.get("a"); value != 42 {
+		t.Errorf("Expected the old value while reloading, got %d", value)
+	}
+
+	cache.forget("a")
+	if cache.load("a", fn) == nil {
+		t.Error("Expected a forgotten key to load again")
+	}
+}
+
+func TestAsyncCacheMaxAge(t *testing.T) {
+	cache := asyncCache[string]{maxAge: time.Minute}
+	fn := func() tea.Msg { return nil }
+
+	cache.set("a", "fresh")
+	if cache.load("a", fn) != nil {
+		t.Error("Expected a fresh value not to load again")
+	}
+	cache.entries["a"] = cacheEntry[string]{value: "old", loaded: true, loadedAt: time.Now().Add(-2 * time.Minute)}
+	if cache.load("a", fn) == nil {
+		t.Error("Expected an expired value to load again")
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Config is the user configuration read from
+// $XDG_CONFIG_HOME/wtree/config.json (or the platform equivalent).
+type Config struct {
+	Keys   KeysConfig       `json:"keys"`
+	Theme  string           `json:"theme"` // "auto", a built-in theme or a key of Themes
+	Themes map[string]Theme `json:"themes"`
+	ASCII  bool             `json:"ascii"` // replace emoji markers with ASCII
+
+	// OpenWith maps a label to a command used to open a worktree; the
+	// worktree path is appended as the last argument.
+	OpenWith map[string]string `json:"open_with"`
+	// Hooks maps a label to a shell command run inside a worktree.
+	Hooks map[string]string `json:"hooks"`
+	// Repos lists repositories offered by "switch repo".
+	Repos []string `json:"repos"`
+	// Refresh configures background fetching and change detection.
+	Refresh RefreshConfig `json:"auto_refresh"`
+	// PullRequests configures `wtree pr` and the PR worktree action.
+	PullRequests PullRequestConfig `json:"pull_requests"`
+	// Forge configures the provider that reports PR, review and CI status.
+	Forge ForgeConfig `json:"forge"`
+	// Tracker proposes branch names from issue keys in the new branch flow.
+	Tracker TrackerConfig `json:"issue_tracker"`
+	// Naming maps a repository (its path or directory name) to branch
+	// naming rules; the "*" entry applies to all other repositories.
+	Naming map[string]NamingRules `json:"branch_naming"`
+	// Scratch configures throwaway worktrees and their expiry.
+	Scratch ScratchConfig `json:"scratch"`
+	// Sparse maps a repository, like Naming, to its sparse-checkout
+	// profiles.
+	Sparse map[string]SparseConfig `json:"sparse_checkout"`
+	// Submodules maps a repository, like Naming, to how submodules of new
+	// worktrees are set up.
+	Submodules map[string]SubmoduleConfig `json:"submodules"`
+	// LFS maps a repository, like Naming, to how Git LFS files of new
+	// worktrees are fetched.
+	LFS map[string]LFSConfig `json:"lfs"`
+	// Sort orders the worktree list: "recent" (the default) or "git".
+	// Pinned worktrees come first either way.
+	Sort string `json:"sort"`
+}
+
+// KeysConfig selects a key binding preset and overrides individual actions.
+// Bindings maps an action name (see keyMap.actions) to the keys that trigger it.
+type KeysConfig struct {
+	Preset   string              `json:"preset"` // "default", "vim" or "emacs"
+	Bindings map[string][]string `json:"bindings"`
+}
+
+func configPath() (string, error) {
+	if path := os.Getenv("WTREE_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "wtree", "config.json"), nil
+}
+
+// loadConfig reads the user configuration. A missing file is not an error
+// and yields the zero Config, which means built-in defaults everywhere.
+func loadConfig() (Config, error) {
+	var cfg Config
+
+	path, err := configPath()
+	if err != nil {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return cfg, nil
+}
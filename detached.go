@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// getTags lists tags newest first as branches of type "tag". The author is
+// the tagger for annotated tags and the commit author for lightweight ones.
+func getTags(repo string) ([]Branch, error) {
+	cmd := exec.Command("git", "-C", repo, "for-each-ref", "--sort=-creatordate",
+		"--format=%(refname:short)|%(creatordate:iso8601)|%(if)%(taggername)%(then)%(taggername)%(else)%(authorname)%(end)",
+		"refs/tags/")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []Branch
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		parts := strings.SplitN(line, "|", 3)
+		if len(parts) == 3 {
+			created, _ := time.Parse("2006-01-02 15:04:05 -0700", parts[1])
+			tags = append(tags, Branch{
+				Name:       parts[0],
+				Type:       "tag",
+				LastCommit: created.Format("2006-01-02 15:04:05"),
+				Author:     parts[2],
+			})
+		}
+	}
+	return tags, nil
+}
+
+type tagsMsg []Branch
+
+// getTagsCmd loads the Tags list. Like branches, a failure leaves the list
+// empty rather than reporting an error.
+func getTagsCmd(repo string) tea.Cmd {
+	return inRepo(repo, func() tea.Msg {
+		tags, err := getTags(repo)
+		if err != nil {
+			return tagsMsg{}
+		}
+		return tagsMsg(tags)
+	})
+}
+
+// getTagsByCommit maps commit SHAs to the tags pointing at them, peeling
+// annotated tags to their commit.
+func getTagsByCommit(repo string) map[string]string {
+	tags := make(map[string]string)
+	cmd := exec.Command("git", "-C", repo, "for-each-ref", "--sort=-creatordate", "--format=%(refname:short)|%(objectname)|%(*objectname)", "refs/tags/")
+	output, err := cmd.Output()
+	if err != nil {
+		return tags
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		parts := strings.SplitN(line, "|", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		commit := parts[1]
+		if parts[2] != "" {
+			commit = parts[2]
+		}
+		// Keep the newest tag when several point at the same commit
+		if _, ok := tags[commit]; !ok {
+			tags[commit] = parts[0]
+		}
+	}
+	return tags
+}
+
+// worktreeRef is what a worktree has checked out: its branch, or for a
+// detached worktree the tag at HEAD or the short SHA.
+func worktreeRef(worktree Worktree) string {
+	switch {
+	case worktree.Branch != "":
+		return worktree.Branch
+	case worktree.Tag != "":
+		return worktree.Tag
+	case len(worktree.Head) > 7:
+		return worktree.Head[:7]
+	}
+	return worktree.Head
+}
+
+// createDetachedWorktree checks out commit (any revision git understands)
+// in a new detached worktree named after label and returns its path.
+func createDetachedWorktree(repo, commit, label string, opts createOptions) (string, error) {
+	worktreePath, err := worktreePathFor(repo, label)
+	if err != nil {
+		return "", err
+	}
+	if err := addWorktree(repo, worktreePath, commit, opts, "--detach"); err != nil {
+		return "", err
+	}
+	return worktreePath, recordCreated(worktreePath)
+}
+
+// createCommitWorktree resolves rev to a commit and creates a detached
+// worktree for it named after the short SHA.
+func createCommitWorktree(repo, rev string, opts createOptions) (string, string, error) {
+	output, err := exec.Command("git", "-C", repo, "rev-parse", "--verify", "--quiet", "--short", rev+"^{commit}").Output()
+	if err != nil {
+		return "", "", fmt.Errorf("'%s' is not a commit", rev)
+	}
+	sha := strings.TrimSpace(string(output))
+	path, err := createDetachedWorktree(repo, sha, sha, opts)
+	return sha, path, err
+}
+
+func createCommitWorktreeCmd(repo, rev string, opts createOptions) tea.Cmd {
+	return func() tea.Msg {
+		sha, path, err := createCommitWorktree(repo, rev, opts)
+		return resultMsg(worktreeCreatedMsg{branch: sha, path: path, detached: true}, err)
+	}
+}
+
+func (m *model) startCommitInput() tea.Cmd {
+	m.enteringCommit = true
+	m.commitInput.SetValue("")
+	return m.commitInput.Focus()
+}
+
+func (m *model) cancelCommitInput() {
+	m.enteringCommit = false
+	m.commitInput.SetValue("")
+	m.commitInput.Blur()
+}
+
+func (m model) confirmCommitInput() (model, tea.Cmd) {
+	rev := strings.TrimSpace(m.commitInput.Value())
+	if rev == "" {
+		return m, nil
+	}
+	opts, err := m.worktreeOptions(m.newSparse)
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("%s %v", markers.Error, err)
+		return m, clearStatusAfterDelay()
+	}
+	m.cancelCommitInput()
+	m.creatingWorktree = true
+	m.creatingForBranch = rev
+	m.statusMessage = fmt.Sprintf("Creating detached worktree at '%s'...", rev)
+	return m, createCommitWorktreeCmd(m.repo, rev, opts)
+}
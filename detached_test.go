@@ -0,0 +1,121 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTagsAndDetachedWorktrees(t *testing.T) {
+	repo := newTestRepo(t)
+	t.Chdir(repo)
+
+	runGit(t, repo, "tag", "v1.0")
+	runGit(t, repo, "commit", "--allow-empty", "-m", "second")
+	runGit(t, repo, "tag", "-a", "v2.0", "-m", "release 2")
+	first := runGit(t, repo, "rev-parse", "--short", "HEAD~1")
+
+	branches, err := getBranches(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, branch := range branches {
+		if branch.Type == "tag" {
+			t.Errorf("Expected tags in a list of their own, got %+v", branch)
+		}
+	}
+	tags, err := getTags(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 2 || tags[0].Type != "tag" || tags[0].Author != "Test User" {
+		t.Fatalf("Expected two tags with an author, got %+v", tags)
+	}
+
+	tagPath, err := createWorktree(".", Branch{Name: "v2.0", Type: "tag"}, createOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(tagPath) != "repo-v2.0" {
+		t.Errorf("Unexpected tag worktree path %s", tagPath)
+	}
+
+	sha, commitPath, err := createCommitWorktree(".", "HEAD~1", createOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sha != first || filepath.Base(commitPath) != "repo-"+first {
+		t.Errorf("Expected a worktree named after %s, got %s at %s", first, sha, commitPath)
+	}
+	if _, _, err := createCommitWorktree(".", "no-such-rev", createOptions{}); err == nil {
+		t.Error("Expected an error for an unknown revision")
+	}
+
+	worktrees, err := getWorktrees(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	refs := make(map[string]string)
+	for _, worktree := range worktrees {
+		refs[filepath.Base(worktree.Path)] = worktreeRef(worktree)
+	}
+	// HEAD~1 is also tagged v1.0, so the tag wins over the SHA
+	if refs["repo-v2.0"] != "v2.0" || refs["repo-"+first] != "v1.0" || refs["repo"] != "main" {
+		t.Errorf("Unexpected worktree refs %v", refs)
+	}
+
+	m := initialModel()
+	m.allWorktrees = worktrees
+	m.filterWorktrees()
+	if view := m.View(); !strings.Contains(view, "detached at v2.0") {
+		t.Errorf("Expected detached worktrees labelled in the view, got:\n%s", view)
+	}
+}
+
+func TestWorktreeRefFallsBackToShortSHA(t *testing.T) {
+	worktree := Worktree{Head: "0123456789abcdef"}
+	if ref := worktreeRef(worktree); ref != "0123456" {
+		t.Errorf("Expected short SHA, got %q", ref)
+	}
+}
+
+func TestTagsView(t *testing.T) {
+	m := initialModel()
+	updated, _ := m.Update(branchesMsg{{Name: "main", Type: "local"}})
+	m = updated.(model)
+	updated, _ = m.Update(tagsMsg{{Name: "v2.0", Type: "tag"}, {Name: "v1.0", Type: "tag"}})
+	m = updated.(model)
+	if len(m.branches) != 1 {
+		t.Errorf("Expected tags to stay out of the branch list, got %v", m.branches)
+	}
+
+	m.switchView("tags")
+	m.startFilter()
+	m.filterInput.SetValue("v1")
+	m.applyFilter()
+	if len(m.tags) != 1 || m.tags[0].Name != "v1.0" {
+		t.Fatalf("Expected v1.0 to match, got %v", m.tags)
+	}
+	if branch, ok := m.selectedBranch(); !ok || branch.Name != "v1.0" {
+		t.Errorf("Expected v1.0 selected, got %+v", branch)
+	}
+	if view := m.View(); !strings.Contains(view, "v1.0") || strings.Contains(view, "main") {
+		t.Errorf("Expected only the matching tag in the view, got:\n%s", view)
+	}
+}
+
+func TestFilterWorktreesByTag(t *testing.T) {
+	m := initialModel()
+	m.allWorktrees = []Worktree{
+		{Path: "/src/repo", Branch: "main", Head: "aaaaaaa"},
+		{Path: "/src/repo-release", Head: "bbbbbbb", Tag: "v3.1"},
+	}
+	m.filterInput.SetValue("v3.1")
+	m.filterWorktrees()
+	if len(m.worktrees) != 1 || m.worktrees[0].Tag != "v3.1" {
+		t.Fatalf("Expected the tagged worktree to match, got %v", m.worktrees)
+	}
+	if match := m.worktreeMatches["/src/repo-release"]; len(match.tag) != 4 {
+		t.Errorf("Expected the tag to be highlighted, got %+v", match)
+	}
+}
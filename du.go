@@ -0,0 +1,290 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// DiskUsage is the size of a worktree on disk, leaving out its .git
+// directory, whose objects are shared with the other worktrees.
+type DiskUsage struct {
+	Total   int64
+	Ignored int64 // files matched by .gitignore, such as node_modules or target/
+}
+
+// ignoredPaths lists the ignored files and directories of the worktree at
+// path, relative to it. Directories that are ignored as a whole are listed
+// once, without their content.
+func ignoredPaths(path string) map[string]bool {
+	ignored := make(map[string]bool)
+	cmd := exec.Command("git", "-C", path, "ls-files", "-z", "--others", "--ignored", "--exclude-standard", "--directory")
+	output, err := cmd.Output()
+	if err != nil {
+		return ignored
+	}
+	for _, entry := range strings.Split(string(output), "\x00") {
+		if entry != "" {
+			ignored[strings.TrimSuffix(entry, "/")] = true
+		}
+	}
+	return ignored
+}
+
+// treeSize adds up the regular files under path. Entries that can't be
+// read are skipped.
+func treeSize(path string) int64 {
+	var size int64
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// measureDiskUsage walks the worktree at path once, counting ignored
+// directories towards both totals without descending into them twice.
+func measureDiskUsage(path string) DiskUsage {
+	var usage DiskUsage
+	ignored := ignoredPaths(path)
+	filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(path, p)
+		rel = filepath.ToSlash(rel)
+		switch {
+		case rel == ".git":
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+		case ignored[rel]:
+			size := treeSize(p)
+			usage.Total += size
+			usage.Ignored += size
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+		case d.Type().IsRegular():
+			if info, err := d.Info(); err == nil {
+				usage.Total += info.Size()
+			}
+		}
+		return nil
+	})
+	return usage
+}
+
+// measureDiskUsages measures the worktrees concurrently and returns their
+// usage in the same order.
+func measureDiskUsages(worktrees []Worktree) []DiskUsage {
+	usages := make([]DiskUsage, len(worktrees))
+	var wg sync.WaitGroup
+	for i, worktree := range worktrees {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			backgroundSlots <- struct{}{}
+			defer func() { <-backgroundSlots }()
+			usages[i] = measureDiskUsage(worktree.Path)
+		}()
+	}
+	wg.Wait()
+	return usages
+}
+
+// formatSize renders a byte count for humans, e.g. "1.2 GB".
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	value := float64(bytes) / unit
+	for _, suffix := range []string{"KB", "MB", "GB"} {
+		if value < unit {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+		value /= unit
+	}
+	return fmt.Sprintf("%.1f TB", value)
+}
+
+// String summarizes the usage for the worktree list, e.g.
+// "1.2 GB, 900.0 MB ignored".
+func (u DiskUsage) String() string {
+	if u.Ignored == 0 {
+		return formatSize(u.Total)
+	}
+	return fmt.Sprintf("%s, %s ignored", formatSize(u.Total), formatSize(u.Ignored))
+}
+
+type diskUsageMsg struct {
+	path  string
+	usage DiskUsage
+}
+
+// refreshDiskUsage starts measuring the worktrees that have no cached
+// usage yet. Sizes stay cached until a cleanup or an explicit re-measure,
+// since walking large trees on every refresh would be too slow.
+func (m *model) refreshDiskUsage() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, wt := range m.allWorktrees {
+		path := wt.Path
+		cmds = append(cmds, m.diskUsage.load(path, inRepo(m.repo, func() tea.Msg {
+			return diskUsageMsg{path: path, usage: measureDiskUsage(path)}
+		})))
+	}
+	return tea.Batch(cmds...)
+}
+
+// remeasureDiskUsage drops the cached usage of all worktrees and measures
+// them again.
+func (m model) remeasureDiskUsage() (model, tea.Cmd) {
+	m.diskUsage.clear()
+	m.statusMessage = "Measuring disk usage..."
+	cmd := tea.Batch(m.refreshDiskUsage(), clearStatusAfterDelay())
+	return m, cmd
+}
+
+// renderDiskUsage shows the cached usage of a worktree for the list.
+func (m model) renderDiskUsage(path string) string {
+	usage, ok := m.diskUsage.get(path)
+	if !ok {
+		return ""
+	}
+	return usage.String()
+}
+
+// cleanIgnored removes the ignored files of the worktree at path, with
+// `git clean -fdX`. Untracked files that aren't ignored are kept.
+func cleanIgnored(path string) error {
+	_, err := gitIn(path, "clean", "-fdX")
+	return err
+}
+
+type ignoredCleanedMsg struct {
+	paths []string
+	freed int64
+}
+
+func cleanIgnoredCmd(paths []string, freed int64) tea.Cmd {
+	return func() tea.Msg {
+		for _, path := range paths {
+			if err := cleanIgnored(path); err != nil {
+				return fmt.Errorf("cleaning %s: %w", filepath.Base(path), err)
+			}
+		}
+		return ignoredCleanedMsg{paths: paths, freed: freed}
+	}
+}
+
+// toggleMark adds the worktree at path to, or removes it from, the ones a
+// cleanup applies to.
+func (m *model) toggleMark(path string) {
+	if m.marked[path] {
+		delete(m.marked, path)
+	} else {
+		m.marked[path] = true
+	}
+}
+
+// cleanTargets are the marked worktrees in list order, or the one under
+// the cursor when none are marked.
+func (m model) cleanTargets() []string {
+	var paths []string
+	for _, wt := range m.allWorktrees {
+		if m.marked[wt.Path] {
+			paths = append(paths, wt.Path)
+		}
+	}
+	if len(paths) == 0 && m.view == "worktrees" && m.cursor < len(m.worktrees) {
+		paths = append(paths, m.worktrees[m.cursor].Path)
+	}
+	return paths
+}
+
+// startClean asks for confirmation before removing the ignored files of
+// the clean targets. Worktrees measured without ignored files are left out.
+func (m *model) startClean() tea.Cmd {
+	var paths []string
+	for _, path := range m.cleanTargets() {
+		if usage, ok := m.diskUsage.get(path); ok && usage.Ignored == 0 {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	if len(paths) == 0 {
+		m.statusMessage = markers.OK + " No ignored files to clean"
+		return clearStatusAfterDelay()
+	}
+	m.confirmingClean = true
+	m.cleanPaths = paths
+	return nil
+}
+
+func (m *model) cancelClean() {
+	m.confirmingClean = false
+	m.cleanPaths = nil
+}
+
+func (m model) confirmClean() (model, tea.Cmd) {
+	paths := m.cleanPaths
+	freed := m.cleanSize()
+	m.cancelClean()
+	m.statusMessage = fmt.Sprintf("Removing ignored files in %d worktree(s)...", len(paths))
+	return m, cleanIgnoredCmd(paths, freed)
+}
+
+// cleanSize is what the pending cleanup frees, as far as it is measured.
+func (m model) cleanSize() int64 {
+	var size int64
+	for _, path := range m.cleanPaths {
+		usage, _ := m.diskUsage.get(path)
+		size += usage.Ignored
+	}
+	return size
+}
+
+func (m model) renderCleanConfirm() string {
+	names := make([]string, len(m.cleanPaths))
+	for i, path := range m.cleanPaths {
+		names[i] = filepath.Base(path)
+	}
+	prompt := fmt.Sprintf("Remove ignored files in %s", strings.Join(names, ", "))
+	if size := m.cleanSize(); size > 0 {
+		prompt += fmt.Sprintf(" (%s)", formatSize(size))
+	}
+	return errorStyle.UnsetPaddingLeft().Render(prompt+"?") + pathStyle.Render(" git clean -fdX cannot be undone")
+}
+
+// runDUCommand prints the disk usage of every worktree, measured
+// concurrently.
+func runDUCommand(cfg Config, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("usage: wtree du")
+	}
+	worktrees, err := getWorktrees(".")
+	if err != nil {
+		return err
+	}
+
+	var total DiskUsage
+	fmt.Printf("%10s %10s  %s\n", "SIZE", "IGNORED", "WORKTREE")
+	for i, usage := range measureDiskUsages(worktrees) {
+		fmt.Printf("%10s %10s  %s\n", formatSize(usage.Total), formatSize(usage.Ignored), worktrees[i].Path)
+		total.Total += usage.Total
+		total.Ignored += usage.Ignored
+	}
+	fmt.Printf("%10s %10s  total\n", formatSize(total.Total), formatSize(total.Ignored))
+	return nil
+}
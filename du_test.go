@@ -0,0 +1,89 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFormatSize(t *testing.T) {
+	tests := map[int64]string{
+		0:               "0 B",
+		1023:            "1023 B",
+		1536:            "1.5 KB",
+		5 * 1024 * 1024: "5.0 MB",
+		3 << 40:         "3.0 TB",
+	}
+	for bytes, want := range tests {
+		if got := formatSize(bytes); got != want {
+			t.Errorf("formatSize(%d) = %q, want %q", bytes, got, want)
+		}
+	}
+}
+
+func TestMeasureAndCleanIgnored(t *testing.T) {
+	repo := newTestRepo(t)
+	t.Chdir(repo)
+
+	writeFile(t, filepath.Join(repo, ".gitignore"), "node_modules/\n*.log\n")
+	runGit(t, repo, "add", ".gitignore")
+	runGit(t, repo, "commit", "-m", "ignore")
+	if err := os.MkdirAll(filepath.Join(repo, "node_modules/left-pad"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(repo, "node_modules/left-pad/index.js"), "0123456789")
+	writeFile(t, filepath.Join(repo, "debug.log"), "01234")
+	writeFile(t, filepath.Join(repo, "notes.txt"), "012")
+
+	tracked := int64(len("hello\n") + len("node_modules/\n*.log\n"))
+	usage := measureDiskUsage(repo)
+	if usage.Ignored != 15 || usage.Total != tracked+15+3 {
+		t.Errorf("Expected 15 ignored bytes of %d, got %+v", tracked+18, usage)
+	}
+
+	if err := cleanIgnored(repo); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "node_modules")); !os.IsNotExist(err) {
+		t.Error("Expected node_modules to be removed")
+	}
+	if content := readFile(t, filepath.Join(repo, "notes.txt")); content != "012" {
+		t.Error("Expected untracked files that aren't ignored to be kept")
+	}
+	if usage := measureDiskUsage(repo); usage.Ignored != 0 {
+		t.Errorf("Expected nothing ignored after cleaning, got %+v", usage)
+	}
+}
+
+func TestStartCleanConfirmsMarkedWorktrees(t *testing.T) {
+	m := initialModel()
+	m.allWorktrees = []Worktree{{Path: "/src/repo"}, {Path: "/src/repo-a"}, {Path: "/src/repo-b"}}
+	m.worktrees = m.allWorktrees
+	m.diskUsage.set("/src/repo-a", DiskUsage{Total: 300, Ignored: 200})
+	m.diskUsage.set("/src/repo-b", DiskUsage{Total: 100})
+
+	if targets := m.cleanTargets(); len(targets) != 1 || targets[0] != "/src/repo" {
+		t.Errorf("Expected the worktree under the cursor without marks, got %v", targets)
+	}
+
+	m.toggleMark("/src/repo-b")
+	m.toggleMark("/src/repo-a")
+	m.startClean()
+	if !m.confirmingClean || len(m.cleanPaths) != 1 || m.cleanPaths[0] != "/src/repo-a" {
+		t.Fatalf("Expected to confirm only the worktree with ignored files, got %v", m.cleanPaths)
+	}
+	if size := m.cleanSize(); size != 200 {
+		t.Errorf("Expected 200 bytes to be freed, got %d", size)
+	}
+
+	m.cancelClean()
+	if m.confirmingClean || !m.marked["/src/repo-a"] {
+		t.Error("Expected cancel to close the prompt and keep the marks")
+	}
+
+	m.toggleMark("/src/repo-a")
+	m.startClean()
+	if m.confirmingClean {
+		t.Error("Expected nothing to confirm when no marked worktree has ignored files")
+	}
+}
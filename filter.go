@@ -0,0 +1,275 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sahilm/fuzzy"
+)
+
+// filterQuery is a parsed filter string. Plain words are fuzzy matched,
+// while "remote:", "author:", "dirty:" and "label:" prefixes narrow the
+// list.
+type filterQuery struct {
+	text   string
+	remote string
+	author string
+	dirty  *bool
+	label  string
+}
+
+// worktreeMatch holds the matched byte offsets for each searchable
+// worktree field so they can be highlighted independently.
+type worktreeMatch struct {
+	path   []int
+	branch []int
+	head   []int
+	tag    []int
+	note   []int
+}
+
+func parseFilterQuery(input string) filterQuery {
+	var q filterQuery
+	var words []string
+
+	for _, field := range strings.Fields(input) {
+		key, value, found := strings.Cut(field, ":")
+		if !found {
+			words = append(words, field)
+			continue
+		}
+		switch strings.ToLower(key) {
+		case "remote":
+			q.remote = value
+		case "author":
+			q.author = value
+		case "dirty":
+			dirty := value != "no" && value != "false" && value != "0"
+			q.dirty = &dirty
+		case "label":
+			q.label = value
+		default:
+			words = append(words, field)
+		}
+	}
+
+	q.text = strings.Join(words, " ")
+	return q
+}
+
+func (q filterQuery) isEmpty() bool {
+	return q.text == "" && q.remote == "" && q.author == "" && q.dirty == nil && q.label == ""
+}
+
+func (q filterQuery) matchesBranch(branch Branch) bool {
+	if q.remote != "" {
+		if branch.Type != "remote" || !strings.HasPrefix(branch.Name, q.remote) {
+			return false
+		}
+	}
+	if q.author != "" && !containsFold(branch.Author, q.author) {
+		return false
+	}
+	// Only worktrees carry labels
+	if q.label != "" {
+		return false
+	}
+	return true
+}
+
+func (q filterQuery) matchesWorktree(worktree Worktree) bool {
+	if q.remote != "" && !strings.HasPrefix(worktree.Upstream, q.remote) {
+		return false
+	}
+	if q.author != "" && !containsFold(worktree.Author, q.author) {
+		return false
+	}
+	if q.dirty != nil && worktree.Dirty != *q.dirty {
+		return false
+	}
+	if q.label != "" && !hasLabel(worktree.Meta.Labels, q.label) {
+		return false
+	}
+	return true
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// worktreeSearchText joins the searchable worktree fields. The offsets
+// of each field are needed to split fuzzy match indexes back apart.
+// Newlines in the note become spaces, which keeps its offsets intact.
+func worktreeSearchText(worktree Worktree) string {
+	note := strings.ReplaceAll(worktree.Meta.Note, "\n", " ")
+	return worktree.Path + " " + worktree.Branch + " " + worktree.Head + " " + note + " " + worktree.Tag
+}
+
+func splitWorktreeMatch(worktree Worktree, indexes []int) worktreeMatch {
+	var match worktreeMatch
+	branchStart := len(worktree.Path) + 1
+	headStart := branchStart + len(worktree.Branch) + 1
+	noteStart := headStart + len(worktree.Head) + 1
+	tagStart := noteStart + len(worktree.Meta.Note) + 1
+
+	for _, i := range indexes {
+		switch {
+		case i < len(worktree.Path):
+			match.path = append(match.path, i)
+		case i >= branchStart && i < headStart-1:
+			match.branch = append(match.branch, i-branchStart)
+		case i >= headStart && i < noteStart-1:
+			match.head = append(match.head, i-headStart)
+		case i >= noteStart && i < tagStart-1:
+			match.note = append(match.note, i-noteStart)
+		case i >= tagStart:
+			match.tag = append(match.tag, i-tagStart)
+		}
+	}
+	return match
+}
+
+func (m *model) applyFilter() {
+	if m.view == "worktrees" {
+		m.filterWorktrees()
+	} else if m.view == "stashes" {
+		m.filterStashes()
+	} else if m.view == "tags" {
+		m.filterTags()
+	} else {
+		m.filterBranches()
+	}
+}
+
+func (m *model) filterBranches() {
+	selected := m.selectionID("branches")
+	defer func() { m.restoreSelection("branches", selected) }()
+	m.branches, m.branchMatches = filterBranchList(m.allBranches, m.filterInput.Value())
+}
+
+func (m *model) filterTags() {
+	selected := m.selectionID("tags")
+	defer func() { m.restoreSelection("tags", selected) }()
+	m.tags, m.branchMatches = filterBranchList(m.allTags, m.filterInput.Value())
+}
+
+// filterBranchList applies the filter input to a branch or tag list and
+// returns what matches, with the fuzzy match indexes by name.
+func filterBranchList(all []Branch, input string) ([]Branch, map[string][]int) {
+	query := parseFilterQuery(input)
+	if query.isEmpty() {
+		return all, nil
+	}
+
+	candidates := make([]Branch, 0, len(all))
+	for _, branch := range all {
+		if query.matchesBranch(branch) {
+			candidates = append(candidates, branch)
+		}
+	}
+	if query.text == "" {
+		return candidates, nil
+	}
+
+	// Create a slice of branch names for fuzzy search
+	branchNames := make([]string, len(candidates))
+	for i, branch := range candidates {
+		branchNames[i] = branch.Name
+	}
+
+	matches := fuzzy.Find(query.text, branchNames)
+	filtered := make([]Branch, 0, len(matches))
+	branchMatches := make(map[string][]int, len(matches))
+	for _, match := range matches {
+		filtered = append(filtered, candidates[match.Index])
+		branchMatches[match.Str] = match.MatchedIndexes
+	}
+	return filtered, branchMatches
+}
+
+func (m *model) filterWorktrees() {
+	selected := m.selectionID("worktrees")
+	if m.focusWorktree != "" {
+		selected = m.focusWorktree
+	}
+	defer func() {
+		m.restoreSelection("worktrees", selected)
+		if m.focusWorktree != "" && m.selectionID("worktrees") == m.focusWorktree {
+			m.focusWorktree = ""
+		}
+	}()
+
+	query := parseFilterQuery(m.filterInput.Value())
+	m.worktreeMatches = nil
+	if query.isEmpty() {
+		m.worktrees = m.allWorktrees
+		return
+	}
+
+	candidates := make([]Worktree, 0, len(m.allWorktrees))
+	for _, worktree := range m.allWorktrees {
+		if query.matchesWorktree(worktree) {
+			candidates = append(candidates, worktree)
+		}
+	}
+
+	filtered := candidates
+	if query.text != "" {
+		searchText := make([]string, len(candidates))
+		for i, worktree := range candidates {
+			searchText[i] = worktreeSearchText(worktree)
+		}
+
+		matches := fuzzy.Find(query.text, searchText)
+		filtered = make([]Worktree, 0, len(matches))
+		m.worktreeMatches = make(map[string]worktreeMatch, len(matches))
+		for _, match := range matches {
+			worktree := candidates[match.Index]
+			filtered = append(filtered, worktree)
+			m.worktreeMatches[worktree.Path] = splitWorktreeMatch(worktree, match.MatchedIndexes)
+		}
+	}
+
+	m.worktrees = filtered
+}
+
+// highlightMatches renders s with the bytes at the given offsets drawn in
+// matchStyle and everything else in base.
+func highlightMatches(s string, indexes []int, base lipgloss.Style) string {
+	if len(indexes) == 0 {
+		return base.Render(s)
+	}
+
+	matched := make(map[int]struct{}, len(indexes))
+	for _, i := range indexes {
+		matched[i] = struct{}{}
+	}
+	highlight := matchStyle.Inherit(base)
+
+	var out strings.Builder
+	var run strings.Builder
+	runMatched := false
+	flush := func() {
+		if run.Len() == 0 {
+			return
+		}
+		if runMatched {
+			out.WriteString(highlight.Render(run.String()))
+		} else {
+			out.WriteString(base.Render(run.String()))
+		}
+		run.Reset()
+	}
+
+	for i, r := range s {
+		_, isMatch := matched[i]
+		if isMatch != runMatched {
+			flush()
+			runMatched = isMatch
+		}
+		run.WriteRune(r)
+	}
+	flush()
+
+	return out.String()
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestParseFilterQuery(t *testing.T) {
+	tests := []struct {
+		input  string
+		text   string
+		remote string
+		author string
+		dirty  string // "", "yes" or "no"
+	}{
+		{"feature", "feature", "", "", ""},
+		{"remote:origin fix", "fix", "origin", "", ""},
+		{"author:alice dirty:yes", "", "", "alice", "yes"},
+		{"dirty:no api", "api", "", "", "no"},
+		{"unknown:prefix", "unknown:prefix", "", "", ""},
+	}
+
+	for _, test := range tests {
+		q := parseFilterQuery(test.input)
+		if q.text != test.text || q.remote != test.remote || q.author != test.author {
+			t.Errorf("parseFilterQuery(%q) = %+v", test.input, q)
+		}
+		dirty := ""
+		if q.dirty != nil {
+			dirty = "no"
+			if *q.dirty {
+				dirty = "yes"
+			}
+		}
+		if dirty != test.dirty {
+			t.Errorf("parseFilterQuery(%q) dirty = %q, expected %q", test.input, dirty, test.dirty)
+		}
+	}
+}
+
+func TestFilterWorktrees(t *testing.T) {
+	m := initialModel()
+	m.allWorktrees = []Worktree{
+		{Path: "/src/repo", Branch: "main", Head: "aaa111", Author: "Alice"},
+		{Path: "/src/repo-feature", Branch: "feature", Head: "bbb222", Author: "Bob", Dirty: true},
+		{Path: "/src/repo-fix", Branch: "fix/login", Head: "ccc333", Author: "Alice", Upstream: "origin/fix/login"},
+	}
+
+	m.filterInput.SetValue("feature")
+	m.filterWorktrees()
+	if len(m.worktrees) != 1 || m.worktrees[0].Branch != "feature" {
+		t.Fatalf("Expected only the feature worktree, got %v", m.worktrees)
+	}
+	if len(m.worktreeMatches["/src/repo-feature"].path) == 0 {
+		t.Error("Expected matched indexes for the feature worktree path")
+	}
+
+	m.filterInput.SetValue("ccc333")
+	m.filterWorktrees()
+	if len(m.worktrees) != 1 || m.worktrees[0].Branch != "fix/login" {
+		t.Errorf("Expected HEAD to be searchable, got %v", m.worktrees)
+	}
+
+	m.filterInput.SetValue("dirty:yes")
+	m.filterWorktrees()
+	if len(m.worktrees) != 1 || !m.worktrees[0].Dirty {
+		t.Errorf("Expected only the dirty worktree, got %v", m.worktrees)
+	}
+
+	m.filterInput.SetValue("author:alice remote:origin")
+	m.filterWorktrees()
+	if len(m.worktrees) != 1 || m.worktrees[0].Branch != "fix/login" {
+		t.Errorf("Expected author and remote qualifiers to combine, got %v", m.worktrees)
+	}
+
+	m.filterInput.SetValue("")
+	m.filterWorktrees()
+	if len(m.worktrees) != len(m.allWorktrees) {
+		t.Errorf("Expected all worktrees with empty filter, got %d", len(m.worktrees))
+	}
+}
+
+func TestFilterBranchesRemoteQualifier(t *testing.T) {
+	m := initialModel()
+	m.allBranches = []Branch{
+		{Name: "main", Type: "local"},
+		{Name: "origin/main", Type: "remote"},
+		{Name: "upstream/main", Type: "remote"},
+	}
+
+	m.filterInput.SetValue("remote:upstream main")
+	m.filterBranches()
+	if len(m.branches) != 1 || m.branches[0].Name != "upstream/main" {
+		t.Errorf("Expected only upstream/main, got %v", m.branches)
+	}
+}
+
+func TestSplitWorktreeMatch(t *testing.T) {
+	wt := Worktree{Path: "/a/b", Branch: "dev", Head: "123"}
+	// "/a/b dev 123": 0-3 path, 5-7 branch, 9-11 head
+	match := splitWorktreeMatch(wt, []int{1, 5, 6, 10})
+
+	if len(match.path) != 1 || match.path[0] != 1 {
+		t.Errorf("Expected path match [1], got %v", match.path)
+	}
+	if len(match.branch) != 2 || match.branch[0] != 0 || match.branch[1] != 1 {
+		t.Errorf("Expected branch match [0 1], got %v", match.branch)
+	}
+	if len(match.head) != 1 || match.head[0] != 1 {
+		t.Errorf("Expected head match [1], got %v", match.head)
+	}
+
+	wt.Meta.Note = "why\nnow"
+	// "/a/b dev 123 why now": note starts at 13
+	match = splitWorktreeMatch(wt, []int{11, 13, 18})
+	if len(match.head) != 1 || match.head[0] != 2 {
+		t.Errorf("Expected head match [2], got %v", match.head)
+	}
+	if len(match.note) != 2 || match.note[0] != 0 || match.note[1] != 5 {
+		t.Errorf("Expected note match [0 5], got %v", match.note)
+	}
+}
+
+func TestFilterWorktreesByNoteAndLabel(t *testing.T) {
+	m := initialModel()
+	m.allWorktrees = []Worktree{
+		{Path: "/src/repo", Branch: "main", Head: "aaa111"},
+		{Path: "/src/repo-fix-2", Branch: "fix-2", Head: "bbb222", Meta: WorktreeMeta{
+			Note:   "Repro for the flaky\nchecksum upload",
+			Labels: []string{"Blocked", "ci"},
+		}},
+	}
+
+	m.filterInput.SetValue("checksum")
+	m.filterWorktrees()
+	if len(m.worktrees) != 1 || m.worktrees[0].Branch != "fix-2" {
+		t.Fatalf("Expected the note to be searchable, got %v", m.worktrees)
+	}
+
+	m.filterInput.SetValue("label:blocked")
+	m.filterWorktrees()
+	if len(m.worktrees) != 1 || m.worktrees[0].Branch != "fix-2" {
+		t.Errorf("Expected labels to match case-insensitively, got %v", m.worktrees)
+	}
+
+	m.filterInput.SetValue("label:wip")
+	m.filterWorktrees()
+	if len(m.worktrees) != 0 {
+		t.Errorf("Expected no worktree labelled wip, got %v", m.worktrees)
+	}
+}
+
+func TestHighlightMatchesPreservesText(t *testing.T) {
+	// Without a color profile lipgloss renders plain text, so the
+	// highlighted output must read exactly like the input.
+	result := highlightMatches("feature/ünïcode", []int{0, 9}, lipgloss.NewStyle())
+	if result != "feature/ünïcode" {
+		t.Errorf("Expected highlighted text to be unchanged, got %q", result)
+	}
+}
+
+func TestDirtyFilterFollowsBackgroundStatus(t *testing.T) {
+	m := initialModel()
+	m.filterInput.SetValue("dirty:yes")
+
+	newModel, cmd := m.Update(worktreesMsg{{Path: "/src/repo"}, {Path: "/src/repo-feature"}})
+	m = newModel.(model)
+	if cmd == nil || len(m.worktrees) != 0 {
+		t.Fatalf("Expected no dirty worktrees before the status arrives, got %v", m.worktrees)
+	}
+
+	newModel, _ = m.Update(worktreeDirtyMsg{path: "/src/repo-feature", dirty: true})
+	m = newModel.(model)
+	if len(m.worktrees) != 1 || m.worktrees[0].Path != "/src/repo-feature" {
+		t.Fatalf("Expected the dirty worktree to match, got %v", m.worktrees)
+	}
+
+	// A refresh keeps the known state while the status is checked again
+	newModel, _ = m.Update(worktreesMsg{{Path: "/src/repo"}, {Path: "/src/repo-feature"}})
+	m = newModel.(model)
+	if len(m.worktrees) != 1 || !m.worktrees[0].Dirty {
+		t.Errorf("Expected the dirty state to survive a refresh, got %v", m.worktrees)
+	}
+}
@@ -0,0 +1,531 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// PRStatus summarises the pull request for a branch as reported by a forge.
+type PRStatus struct {
+	Number int
+	State  string // "open", "merged", "closed", "none" or "unknown"
+	Review string // "approved", "changes_requested", "pending" or ""
+	CI     string // "success", "failure", "pending" or ""
+	URL    string
+}
+
+var unknownPRStatus = PRStatus{State: "unknown"}
+
+// ForgeProvider looks up pull request status for a branch. Implementations
+// talk to a forge's REST API; tests point them at a local HTTP server.
+type ForgeProvider interface {
+	PRStatus(branch string) (PRStatus, error)
+}
+
+// ForgeConfig selects and configures the forge provider.
+type ForgeConfig struct {
+	Provider string `json:"provider"`  // "github", "gitlab" or "gitea"; empty disables
+	BaseURL  string `json:"base_url"`  // API root, defaults to the public service
+	Repo     string `json:"repo"`      // "owner/name"; detected from the remote when empty
+	Remote   string `json:"remote"`    // remote used for detection, defaults to "origin"
+	TokenEnv string `json:"token_env"` // environment variable holding the API token
+	CacheTTL string `json:"cache_ttl"` // how long statuses are reused, defaults to "2m"
+}
+
+const defaultForgeCacheTTL = 2 * time.Minute
+
+func (c ForgeConfig) cacheTTL() (time.Duration, error) {
+	if c.CacheTTL == "" {
+		return defaultForgeCacheTTL, nil
+	}
+	d, err := time.ParseDuration(c.CacheTTL)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid forge cache_ttl %q", c.CacheTTL)
+	}
+	return d, nil
+}
+
+// newForgeProvider builds the configured provider for the repository in
+// dir, or returns nil when no provider is configured.
+func newForgeProvider(dir string, cfg ForgeConfig) (ForgeProvider, error) {
+	if cfg.Provider == "" {
+		return nil, nil
+	}
+
+	repo, err := detectRepo(dir, cfg.Repo, cfg.Remote)
+	if err != nil {
+		return nil, err
+	}
+
+	client := forgeClient{
+		http: &http.Client{Timeout: 10 * time.Second},
+		repo: repo,
+	}
+
+	switch cfg.Provider {
+	case "github":
+		client.baseURL = defaultString(cfg.BaseURL, "https://api.github.com")
+		client.token = os.Getenv(defaultString(cfg.TokenEnv, "GITHUB_TOKEN"))
+		client.authHeader = "Authorization"
+		client.authPrefix = "Bearer "
+		return githubProvider{client}, nil
+	case "gitlab":
+		client.baseURL = defaultString(cfg.BaseURL, "https://gitlab.com") + "/api/v4"
+		client.token = os.Getenv(defaultString(cfg.TokenEnv, "GITLAB_TOKEN"))
+		client.authHeader = "PRIVATE-TOKEN"
+		return gitlabProvider{client}, nil
+	case "gitea":
+		if cfg.BaseURL == "" {
+			return nil, fmt.Errorf("the gitea provider needs a base_url")
+		}
+		client.baseURL = cfg.BaseURL + "/api/v1"
+		client.token = os.Getenv(defaultString(cfg.TokenEnv, "GITEA_TOKEN"))
+		client.authHeader = "Authorization"
+		client.authPrefix = "token "
+		return giteaProvider{client}, nil
+	}
+	return nil, fmt.Errorf("unknown forge provider %q (expected github, gitlab or gitea)", cfg.Provider)
+}
+
+// unavailableForge stands in for a provider that couldn't be set up, so
+// a broken forge config shows unknown statuses instead of stopping wtree.
+type unavailableForge struct{ err error }
+
+func (f unavailableForge) PRStatus(string) (PRStatus, error) {
+	return unknownPRStatus, f.err
+}
+
+// loadForgeProvider is newForgeProvider for the TUI, which degrades errors
+// to an unavailableForge.
+func loadForgeProvider(dir string, cfg ForgeConfig) ForgeProvider {
+	provider, err := newForgeProvider(dir, cfg)
+	if err != nil {
+		return unavailableForge{err}
+	}
+	return provider
+}
+
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// detectRepo returns repo when set, otherwise the "owner/name" path of
+// the given remote (default "origin") of the repository in dir.
+func detectRepo(dir, repo, remote string) (string, error) {
+	if repo != "" {
+		return repo, nil
+	}
+	remote = defaultString(remote, "origin")
+	output, err := exec.Command("git", "-C", dir, "remote", "get-url", remote).Output()
+	if err != nil {
+		return "", fmt.Errorf("could not read URL of remote %s: %w", remote, err)
+	}
+	return repoPathFromURL(strings.TrimSpace(string(output)))
+}
+
+// repoPathFromURL extracts "owner/name" (or "group/sub/name") from an SSH
+// or HTTPS remote URL.
+func repoPathFromURL(remoteURL string) (string, error) {
+	path := remoteURL
+	if u, err := url.Parse(remoteURL); err == nil && u.Scheme != "" {
+		path = u.Path
+	} else if _, after, found := strings.Cut(remoteURL, ":"); found {
+		// scp-like syntax: git@host:owner/name.git
+		path = after
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if !strings.Contains(path, "/") {
+		return "", fmt.Errorf("could not determine repository from remote URL %q", remoteURL)
+	}
+	return path, nil
+}
+
+// forgeClient holds what every REST provider needs to make requests.
+type forgeClient struct {
+	http       *http.Client
+	baseURL    string
+	repo       string
+	token      string
+	authHeader string
+	authPrefix string
+}
+
+func (c forgeClient) getJSON(path string, into interface{}) error {
+	return c.doJSON("GET", path, nil, into)
+}
+
+func (c forgeClient) postJSON(path string, body, into interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return c.doJSON("POST", path, bytes.NewReader(data), into)
+}
+
+func (c forgeClient) doJSON(method, path string, body io.Reader, into interface{}) error {
+	req, err := http.NewRequest(method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set(c.authHeader, c.authPrefix+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: %s", method, path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(into)
+}
+
+// reviewState folds individual review states (latest per reviewer) into
+// a single summary.
+func reviewState(latest map[string]string, approved, changesRequested string) string {
+	state := "pending"
+	for _, s := range latest {
+		switch s {
+		case changesRequested:
+			return "changes_requested"
+		case approved:
+			state = "approved"
+		}
+	}
+	return state
+}
+
+func ciState(state string) string {
+	switch state {
+	case "success":
+		return "success"
+	case "failure", "failed", "error", "canceled":
+		return "failure"
+	case "pending", "running", "created", "waiting_for_resource", "preparing", "scheduled":
+		return "pending"
+	}
+	return ""
+}
+
+// checkRunState maps a GitHub check run to a CI state. Runs that haven't
+// completed are pending.
+func checkRunState(status, conclusion string) string {
+	if status != "completed" {
+		return "pending"
+	}
+	switch conclusion {
+	case "success", "neutral", "skipped":
+		return "success"
+	case "failure", "timed_out", "cancelled", "action_required":
+		return "failure"
+	}
+	return ""
+}
+
+// mergeCIStates combines several CI states: any failure fails, otherwise
+// anything pending is pending.
+func mergeCIStates(states []string) string {
+	merged := ""
+	for _, state := range states {
+		switch {
+		case state == "failure":
+			return "failure"
+		case state == "pending":
+			merged = "pending"
+		case state == "success" && merged == "":
+			merged = "success"
+		}
+	}
+	return merged
+}
+
+type githubProvider struct{ forgeClient }
+
+func (p githubProvider) PRStatus(branch string) (PRStatus, error) {
+	owner, _, _ := strings.Cut(p.repo, "/")
+
+	var pulls []struct {
+		Number   int     `json:"number"`
+		State    string  `json:"state"`
+		MergedAt *string `json:"merged_at"`
+		HTMLURL  string  `json:"html_url"`
+		Head     struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+	}
+	query := url.Values{"head": {owner + ":" + branch}, "state": {"all"}}
+	if err := p.getJSON("/repos/"+p.repo+"/pulls?"+query.Encode(), &pulls); err != nil {
+		return unknownPRStatus, err
+	}
+	if len(pulls) == 0 {
+		return PRStatus{State: "none"}, nil
+	}
+
+	pr := pulls[0]
+	status := PRStatus{Number: pr.Number, State: pr.State, URL: pr.HTMLURL}
+	if pr.MergedAt != nil {
+		status.State = "merged"
+	}
+
+	var reviews []struct {
+		User struct {
+			Login string `json:"login"`
+		} `json:"user"`
+		State string `json:"state"`
+	}
+	if err := p.getJSON(fmt.Sprintf("/repos/%s/pulls/%d/reviews", p.repo, pr.Number), &reviews); err == nil {
+		latest := make(map[string]string)
+		for _, r := range reviews {
+			if r.State != "COMMENTED" {
+				latest[r.User.Login] = r.State
+			}
+		}
+		status.Review = reviewState(latest, "APPROVED", "CHANGES_REQUESTED")
+	}
+
+	// CI reports either through the legacy status API or through check runs
+	// (GitHub Actions), so both are read and merged.
+	var states []string
+	var combined struct {
+		State      string `json:"state"`
+		TotalCount int    `json:"total_count"`
+	}
+	// Without any statuses the combined state is "pending", which says nothing
+	if err := p.getJSON(fmt.Sprintf("/repos/%s/commits/%s/status", p.repo, pr.Head.SHA), &combined); err == nil && combined.TotalCount > 0 {
+		states = append(states, ciState(combined.State))
+	}
+	var checks struct {
+		CheckRuns []struct {
+			Status     string `json:"status"`
+			Conclusion string `json:"conclusion"`
+		} `json:"check_runs"`
+	}
+	if err := p.getJSON(fmt.Sprintf("/repos/%s/commits/%s/check-runs", p.repo, pr.Head.SHA), &checks); err == nil {
+		for _, run := range checks.CheckRuns {
+			states = append(states, checkRunState(run.Status, run.Conclusion))
+		}
+	}
+	status.CI = mergeCIStates(states)
+
+	return status, nil
+}
+
+type gitlabProvider struct{ forgeClient }
+
+func (p gitlabProvider) PRStatus(branch string) (PRStatus, error) {
+	project := "/projects/" + url.PathEscape(p.repo)
+
+	var mrs []struct {
+		IID    int    `json:"iid"`
+		State  string `json:"state"`
+		WebURL string `json:"web_url"`
+	}
+	query := url.Values{"source_branch": {branch}}
+	if err := p.getJSON(project+"/merge_requests?"+query.Encode(), &mrs); err != nil {
+		return unknownPRStatus, err
+	}
+	if len(mrs) == 0 {
+		return PRStatus{State: "none"}, nil
+	}
+
+	mr := mrs[0]
+	status := PRStatus{Number: mr.IID, State: mr.State, URL: mr.WebURL}
+	if mr.State == "opened" {
+		status.State = "open"
+	}
+
+	var approvals struct {
+		Approved bool `json:"approved"`
+	}
+	if err := p.getJSON(fmt.Sprintf("%s/merge_requests/%d/approvals", project, mr.IID), &approvals); err == nil {
+		status.Review = "pending"
+		if approvals.Approved {
+			status.Review = "approved"
+		}
+	}
+
+	var detail struct {
+		HeadPipeline *struct {
+			Status string `json:"status"`
+		} `json:"head_pipeline"`
+	}
+	if err := p.getJSON(fmt.Sprintf("%s/merge_requests/%d", project, mr.IID), &detail); err == nil && detail.HeadPipeline != nil {
+		status.CI = ciState(detail.HeadPipeline.Status)
+	}
+
+	return status, nil
+}
+
+type giteaProvider struct{ forgeClient }
+
+// giteaPageSize is the number of pull requests asked for per page, which
+// is also Gitea's default maximum.
+const giteaPageSize = 50
+
+type giteaPull struct {
+	Number  int    `json:"number"`
+	State   string `json:"state"`
+	Merged  bool   `json:"merged"`
+	HTMLURL string `json:"html_url"`
+	Head    struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+}
+
+// findPull pages through the pull requests, newest first, until one has
+// branch as its head. Gitea can't filter the list by head.
+func (p giteaProvider) findPull(branch string) (giteaPull, bool, error) {
+	for page := 1; ; page++ {
+		var pulls []giteaPull
+		query := url.Values{"state": {"all"}, "limit": {fmt.Sprint(giteaPageSize)}, "page": {fmt.Sprint(page)}}
+		if err := p.getJSON("/repos/"+p.repo+"/pulls?"+query.Encode(), &pulls); err != nil {
+			return giteaPull{}, false, err
+		}
+		for _, pr := range pulls {
+			if pr.Head.Ref == branch {
+				return pr, true, nil
+			}
+		}
+		if len(pulls) < giteaPageSize {
+			return giteaPull{}, false, nil
+		}
+	}
+}
+
+func (p giteaProvider) PRStatus(branch string) (PRStatus, error) {
+	pr, found, err := p.findPull(branch)
+	if err != nil {
+		return unknownPRStatus, err
+	}
+	if !found {
+		return PRStatus{State: "none"}, nil
+	}
+
+	status := PRStatus{Number: pr.Number, State: pr.State, URL: pr.HTMLURL}
+	if pr.Merged {
+		status.State = "merged"
+	}
+
+	var reviews []struct {
+		User struct {
+			Login string `json:"login"`
+		} `json:"user"`
+		State string `json:"state"`
+	}
+	if err := p.getJSON(fmt.Sprintf("/repos/%s/pulls/%d/reviews", p.repo, pr.Number), &reviews); err == nil {
+		latest := make(map[string]string)
+		for _, r := range reviews {
+			if r.State == "APPROVED" || r.State == "REQUEST_CHANGES" {
+				latest[r.User.Login] = r.State
+			}
+		}
+		status.Review = reviewState(latest, "APPROVED", "REQUEST_CHANGES")
+	}
+
+	var combined struct {
+		State string `json:"state"`
+	}
+	if err := p.getJSON(fmt.Sprintf("/repos/%s/commits/%s/status", p.repo, pr.Head.SHA), &combined); err == nil {
+		status.CI = ciState(combined.State)
+	}
+	return status, nil
+}
+
+// prStatusMsg carries the result of a forge lookup for one branch.
+type prStatusMsg struct {
+	branch string
+	status PRStatus
+}
+
+// prStatusEntry is a cached forge lookup; a zero status with a recent
+// fetchedAt means a lookup is still in flight.
+type prStatusEntry struct {
+	status    PRStatus
+	fetchedAt time.Time
+}
+
+func fetchPRStatusCmd(provider ForgeProvider, branch string) tea.Cmd {
+	return func() tea.Msg {
+		status, err := provider.PRStatus(branch)
+		if err != nil {
+			// Forge errors never surface as failures, only as "unknown"
+			status = unknownPRStatus
+		}
+		return prStatusMsg{branch: branch, status: status}
+	}
+}
+
+// refreshPRStatuses starts lookups for worktree branches whose cached
+// status is missing or older than the TTL.
+func (m model) refreshPRStatuses() tea.Cmd {
+	if m.forge == nil {
+		return nil
+	}
+
+	ttl, _ := m.config.Forge.cacheTTL()
+	now := time.Now()
+	var cmds []tea.Cmd
+	for _, wt := range m.allWorktrees {
+		if wt.Branch == "" {
+			continue
+		}
+		if entry, ok := m.prStatuses[wt.Branch]; ok && now.Sub(entry.fetchedAt) < ttl {
+			continue
+		}
+		m.prStatuses[wt.Branch] = prStatusEntry{status: unknownPRStatus, fetchedAt: now}
+		cmds = append(cmds, inRepo(m.repo, fetchPRStatusCmd(m.forge, wt.Branch)))
+	}
+	return tea.Batch(cmds...)
+}
+
+// renderPRBadge renders the cached PR status of a branch for the list.
+func (m model) renderPRBadge(branch string) string {
+	entry, ok := m.prStatuses[branch]
+	if !ok || entry.status.State == "none" {
+		return ""
+	}
+	status := entry.status
+	if status.State == "unknown" {
+		return pathStyle.Render("PR ?")
+	}
+
+	parts := []string{fmt.Sprintf("PR #%d %s", status.Number, status.State)}
+	style := statusStyle.UnsetPaddingLeft()
+	switch {
+	case status.State != "open":
+		style = pathStyle
+	case status.Review == "changes_requested" || status.CI == "failure":
+		style = errorStyle.UnsetPaddingLeft()
+	case status.CI == "pending" || status.Review == "pending":
+		style = pendingStyle.UnsetPaddingLeft()
+	}
+	if status.Review != "" {
+		parts = append(parts, strings.ReplaceAll(status.Review, "_", " "))
+	}
+	if status.CI != "" {
+		parts = append(parts, "CI "+status.CI)
+	}
+	return style.Render(strings.Join(parts, " · "))
+}
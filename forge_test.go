@@ -0,0 +1,280 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newForgeServer serves canned JSON responses keyed by request path
+// (without the query string) and records the auth header it saw.
+func newForgeServer(t *testing.T, responses map[string]interface{}) (*httptest.Server, *string) {
+	t.Helper()
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization") + r.Header.Get("PRIVATE-TOKEN")
+		body, ok := responses[r.URL.EscapedPath()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(server.Close)
+	return server, &auth
+}
+
+func TestGitHubPRStatus(t *testing.T) {
+	server, auth := newForgeServer(t, map[string]interface{}{
+		"/repos/acme/app/pulls": []map[string]interface{}{
+			{"number": 12, "state": "open", "merged_at": nil, "html_url": "https://example/12", "head": map[string]string{"sha": "abc"}},
+		},
+		"/repos/acme/app/pulls/12/reviews": []map[string]interface{}{
+			{"user": map[string]string{"login": "ann"}, "state": "CHANGES_REQUESTED"},
+			{"user": map[string]string{"login": "ann"}, "state": "APPROVED"},
+			{"user": map[string]string{"login": "bob"}, "state": "COMMENTED"},
+		},
+		"/repos/acme/app/commits/abc/status": map[string]interface{}{"state": "pending", "total_count": 1},
+	})
+	t.Setenv("TEST_FORGE_TOKEN", "secret")
+
+	provider, err := newForgeProvider(".", ForgeConfig{Provider: "github", BaseURL: server.URL, Repo: "acme/app", TokenEnv: "TEST_FORGE_TOKEN"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, err := provider.PRStatus("feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := PRStatus{Number: 12, State: "open", Review: "approved", CI: "pending", URL: "https://example/12"}
+	if status != expected {
+		t.Errorf("Expected %+v, got %+v", expected, status)
+	}
+	if *auth != "Bearer secret" {
+		t.Errorf("Expected bearer token, got %q", *auth)
+	}
+}
+
+func TestGitLabPRStatus(t *testing.T) {
+	server, auth := newForgeServer(t, map[string]interface{}{
+		"/api/v4/projects/group%2Fsub%2Fapp/merge_requests": []map[string]interface{}{
+			{"iid": 3, "state": "merged", "web_url": "https://example/3"},
+		},
+		"/api/v4/projects/group%2Fsub%2Fapp/merge_requests/3/approvals": map[string]bool{"approved": true},
+		"/api/v4/projects/group%2Fsub%2Fapp/merge_requests/3":           map[string]interface{}{"head_pipeline": map[string]string{"status": "failed"}},
+	})
+	t.Setenv("TEST_FORGE_TOKEN", "secret")
+
+	provider, err := newForgeProvider(".", ForgeConfig{Provider: "gitlab", BaseURL: server.URL, Repo: "group/sub/app", TokenEnv: "TEST_FORGE_TOKEN"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, err := provider.PRStatus("feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := PRStatus{Number: 3, State: "merged", Review: "approved", CI: "failure", URL: "https://example/3"}
+	if status != expected {
+		t.Errorf("Expected %+v, got %+v", expected, status)
+	}
+	if *auth != "secret" {
+		t.Errorf("Expected PRIVATE-TOKEN header, got %q", *auth)
+	}
+}
+
+func TestGiteaPRStatus(t *testing.T) {
+	server, _ := newForgeServer(t, map[string]interface{}{
+		"/api/v1/repos/acme/app/pulls": []map[string]interface{}{
+			{"number": 1, "state": "open", "head": map[string]string{"ref": "other", "sha": "111"}},
+			{"number": 2, "state": "open", "head": map[string]string{"ref": "feature", "sha": "222"}},
+		},
+		"/api/v1/repos/acme/app/pulls/2/reviews": []map[string]interface{}{
+			{"user": map[string]string{"login": "ann"}, "state": "REQUEST_CHANGES"},
+		},
+		"/api/v1/repos/acme/app/commits/222/status": map[string]string{"state": "success"},
+	})
+
+	provider, err := newForgeProvider(".", ForgeConfig{Provider: "gitea", BaseURL: server.URL, Repo: "acme/app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := provider.PRStatus("feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := PRStatus{Number: 2, State: "open", Review: "changes_requested", CI: "success"}
+	if status != expected {
+		t.Errorf("Expected %+v, got %+v", expected, status)
+	}
+
+	status, err = provider.PRStatus("no-such-branch")
+	if err != nil || status.State != "none" {
+		t.Errorf("Expected no PR for unknown branch, got %+v (%v)", status, err)
+	}
+}
+
+func TestGiteaPRStatusPages(t *testing.T) {
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body interface{} = map[string]string{}
+		if r.URL.Path == "/api/v1/repos/acme/app/pulls" {
+			page := r.URL.Query().Get("page")
+			pages = append(pages, page)
+			var pulls []map[string]interface{}
+			if page == "1" {
+				for i := 0; i < giteaPageSize; i++ {
+					pulls = append(pulls, map[string]interface{}{"number": 100 + i, "state": "open", "head": map[string]string{"ref": "other"}})
+				}
+			} else if page == "2" {
+				pulls = append(pulls, map[string]interface{}{"number": 7, "state": "closed", "merged": true, "head": map[string]string{"ref": "old-feature"}})
+			}
+			body = pulls
+		}
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(server.Close)
+
+	provider, _ := newForgeProvider(".", ForgeConfig{Provider: "gitea", BaseURL: server.URL, Repo: "acme/app"})
+	status, err := provider.PRStatus("old-feature")
+	if err != nil || status.Number != 7 || status.State != "merged" {
+		t.Errorf("Expected the merged PR from the second page, got %+v (%v)", status, err)
+	}
+
+	pages = nil
+	if status, _ := provider.PRStatus("no-such-branch"); status.State != "none" || len(pages) != 2 {
+		t.Errorf("Expected no PR after reading both pages, got %+v from pages %v", status, pages)
+	}
+}
+
+func TestGitHubCheckRuns(t *testing.T) {
+	pulls := []map[string]interface{}{
+		{"number": 3, "state": "open", "merged_at": nil, "head": map[string]string{"sha": "def"}},
+	}
+	run := func(status, conclusion string) map[string]interface{} {
+		return map[string]interface{}{"status": status, "conclusion": conclusion}
+	}
+	tests := []struct {
+		legacy   map[string]interface{}
+		runs     []map[string]interface{}
+		expected string
+	}{
+		// GitHub reports "pending" for a commit without any legacy statuses
+		{map[string]interface{}{"state": "pending", "total_count": 0}, []map[string]interface{}{run("completed", "success"), run("completed", "skipped")}, "success"},
+		{map[string]interface{}{"state": "success", "total_count": 1}, []map[string]interface{}{run("in_progress", "")}, "pending"},
+		{map[string]interface{}{"state": "pending", "total_count": 2}, []map[string]interface{}{run("completed", "timed_out")}, "failure"},
+		{map[string]interface{}{"state": "failure", "total_count": 1}, nil, "failure"},
+		{map[string]interface{}{"state": "pending", "total_count": 0}, nil, ""},
+	}
+
+	for _, test := range tests {
+		server, _ := newForgeServer(t, map[string]interface{}{
+			"/repos/acme/app/pulls":                  pulls,
+			"/repos/acme/app/commits/def/status":     test.legacy,
+			"/repos/acme/app/commits/def/check-runs": map[string]interface{}{"total_count": len(test.runs), "check_runs": test.runs},
+		})
+		provider, _ := newForgeProvider(".", ForgeConfig{Provider: "github", BaseURL: server.URL, Repo: "acme/app"})
+		status, err := provider.PRStatus("feature")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status.CI != test.expected {
+			t.Errorf("Expected CI %q for %v and %v, got %q", test.expected, test.legacy, test.runs, status.CI)
+		}
+	}
+}
+
+func TestForgeFailureIsUnknown(t *testing.T) {
+	server, _ := newForgeServer(t, nil)
+	provider, err := newForgeProvider(".", ForgeConfig{Provider: "github", BaseURL: server.URL, Repo: "acme/app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := fetchPRStatusCmd(provider, "feature")().(prStatusMsg)
+	if msg.branch != "feature" || msg.status.State != "unknown" {
+		t.Errorf("Expected unknown status on failure, got %+v", msg)
+	}
+
+	m := initialModel()
+	m.prStatuses["feature"] = prStatusEntry{status: msg.status}
+	if badge := m.renderPRBadge("feature"); !strings.Contains(badge, "PR ?") {
+		t.Errorf("Expected unknown badge, got %q", badge)
+	}
+}
+
+func TestRefreshPRStatusesUsesCache(t *testing.T) {
+	server, _ := newForgeServer(t, nil)
+	provider, _ := newForgeProvider(".", ForgeConfig{Provider: "github", BaseURL: server.URL, Repo: "acme/app"})
+
+	m := initialModel()
+	m.forge = provider
+	m.allWorktrees = []Worktree{{Path: "/repo", Branch: "main"}, {Path: "/detached"}}
+
+	if cmd := m.refreshPRStatuses(); cmd == nil {
+		t.Fatal("Expected a lookup for an uncached branch")
+	}
+	if _, ok := m.prStatuses["main"]; !ok {
+		t.Error("Expected an in-flight cache entry")
+	}
+	if cmd := m.refreshPRStatuses(); cmd != nil {
+		t.Error("Expected cached branch not to be looked up again")
+	}
+}
+
+func TestNewForgeProviderErrors(t *testing.T) {
+	if provider, err := newForgeProvider(".", ForgeConfig{}); provider != nil || err != nil {
+		t.Errorf("Expected no provider when unconfigured, got %v, %v", provider, err)
+	}
+	if _, err := newForgeProvider(".", ForgeConfig{Provider: "bitbucket", Repo: "a/b"}); err == nil {
+		t.Error("Expected error for unknown provider")
+	}
+	if _, err := newForgeProvider(".", ForgeConfig{Provider: "gitea", Repo: "a/b"}); err == nil {
+		t.Error("Expected error for gitea without base_url")
+	}
+
+	// The TUI keeps running and shows unknown statuses instead
+	provider := loadForgeProvider(".", ForgeConfig{Provider: "bitbucket", Repo: "a/b"})
+	if status, err := provider.PRStatus("main"); status != unknownPRStatus || err == nil {
+		t.Errorf("Expected unknown status with the config error, got %+v, %v", status, err)
+	}
+	if provider := loadForgeProvider(".", ForgeConfig{}); provider != nil {
+		t.Errorf("Expected no provider when unconfigured, got %v", provider)
+	}
+}
+
+func TestForgeCacheTTL(t *testing.T) {
+	if ttl, err := (ForgeConfig{}).cacheTTL(); err != nil || ttl != defaultForgeCacheTTL {
+		t.Errorf("Expected the default TTL, got %v (%v)", ttl, err)
+	}
+	if ttl, err := (ForgeConfig{CacheTTL: "30s"}).cacheTTL(); err != nil || ttl != 30*time.Second {
+		t.Errorf("Expected 30s, got %v (%v)", ttl, err)
+	}
+	for _, value := range []string{"2 minutes", "-1m"} {
+		if _, err := (ForgeConfig{CacheTTL: value}).cacheTTL(); err == nil {
+			t.Errorf("Expected %q to be rejected", value)
+		}
+	}
+}
+
+func TestRepoPathFromURL(t *testing.T) {
+	tests := map[string]string{
+		"git@github.com:acme/app.git":             "acme/app",
+		"https://github.com/acme/app":             "acme/app",
+		"ssh://git@gitlab.com/group/sub/app.git":  "group/sub/app",
+		"https://gitea.example.com/acme/app.git/": "acme/app",
+	}
+	for input, expected := range tests {
+		if path, err := repoPathFromURL(input); err != nil || path != expected {
+			t.Errorf("repoPathFromURL(%q) = %q, %v", input, path, err)
+		}
+	}
+	if _, err := repoPathFromURL("app.git"); err == nil {
+		t.Error("Expected error for a remote without owner/name")
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"sort"
@@ -13,41 +14,61 @@ import (
 
 type worktreesMsg []Worktree
 type branchesMsg []Branch
-type newBranchCreatedMsg struct{}
+type newBranchCreatedMsg struct{ path string }
 type newBranchCreatingMsg struct{ branchName string }
 type worktreeDeletedMsg struct{}
 type deletingWorktreeMsg struct{ path string }
 type worktreeCreatedMsg struct {
-	branch string
+	branch   string // branch name, or the tag/SHA of a detached worktree
+	path     string
+	detached bool
+}
+type worktreesPrunedMsg struct{}
+type hookFinishedMsg struct {
+	name string
+	path string
+}
+type repoSwitchedMsg struct{ path string }
+
+// repoMsg is the message of a command started for repo. Update drops it
+// when another repository has been opened since, so results that arrive
+// late don't end up in the lists or caches of the new one.
+type repoMsg struct {
+	repo string
+	msg  tea.Msg
 }
 
-func getWorktreesCmd() tea.Cmd {
+// inRepo tags the message of cmd with repo.
+func inRepo(repo string, cmd tea.Cmd) tea.Cmd {
 	return func() tea.Msg {
-		worktrees, err := getWorktrees()
+		return repoMsg{repo: repo, msg: cmd()}
+	}
+}
+
+func getWorktreesCmd(repo string) tea.Cmd {
+	return inRepo(repo, func() tea.Msg {
+		worktrees, err := getWorktrees(repo)
 		if err != nil {
 			return worktreesMsg{}
 		}
 		return worktreesMsg(worktrees)
-	}
+	})
 }
 
-func getBranchesCmd() tea.Cmd {
-	return func() tea.Msg {
-		branches, err := getBranches()
+func getBranchesCmd(repo string) tea.Cmd {
+	return inRepo(repo, func() tea.Msg {
+		branches, err := getBranches(repo)
 		if err != nil {
 			return branchesMsg{}
 		}
 		return branchesMsg(branches)
-	}
+	})
 }
 
-func createWorktreeCmd(branch Branch) tea.Cmd {
+func createWorktreeCmd(repo string, branch Branch, opts createOptions) tea.Cmd {
 	return func() tea.Msg {
-		err := createWorktree(branch)
-		if err != nil {
-			return err
-		}
-		return worktreeCreatedMsg{branch: branch.Name}
+		path, err := createWorktree(repo, branch, opts)
+		return resultMsg(worktreeCreatedMsg{branch: branch.Name, path: path, detached: branch.Type == "tag"}, err)
 	}
 }
 
@@ -58,44 +79,79 @@ func deleteWorktreeCmd(worktree Worktree) tea.Cmd {
 	}
 }
 
-func performDeleteWorktreeCmd(worktree Worktree) tea.Cmd {
+func performDeleteWorktreeCmd(repo string, worktree Worktree) tea.Cmd {
+	return func() tea.Msg {
+		return resultMsg(worktreeDeletedMsg{}, deleteWorktree(repo, worktree))
+	}
+}
+
+func openWorktreeCmd(repo string, worktree Worktree) tea.Cmd {
 	return func() tea.Msg {
-		err := deleteWorktree(worktree)
+		err := openWorktree(worktree)
 		if err != nil {
 			return err
 		}
-		return worktreeDeletedMsg{}
+		// Reload so the list reflects the new recency order
+		return getWorktreesCmd(repo)()
 	}
 }
 
-func openWorktreeCmd(worktree Worktree) tea.Cmd {
+func openWorktreeWithCmd(repo string, worktree Worktree, command string) tea.Cmd {
 	return func() tea.Msg {
-		err := openWorktree(worktree)
+		err := openWorktreeWith(worktree, command)
 		if err != nil {
 			return err
 		}
-		return nil
+		// Reload so the list reflects the new recency order
+		return getWorktreesCmd(repo)()
 	}
 }
 
-func createNewBranchWorktreeCmd(branchName string) tea.Cmd {
+func pruneWorktreesCmd(repo string) tea.Cmd {
 	return func() tea.Msg {
-		return newBranchCreatingMsg{branchName: branchName}
+		err := pruneWorktrees(repo)
+		if err != nil {
+			return err
+		}
+		return worktreesPrunedMsg{}
 	}
 }
 
-func performCreateNewBranchWorktreeCmd(branchName string) tea.Cmd {
+func runHookCmd(worktree Worktree, name, command string) tea.Cmd {
 	return func() tea.Msg {
-		err := createNewBranchWorktree(branchName)
+		err := runHook(worktree, command)
+		if err != nil {
+			return fmt.Errorf("hook '%s' failed: %w", name, err)
+		}
+		return hookFinishedMsg{name: name, path: worktree.Path}
+	}
+}
+
+func switchRepoCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		root, err := resolveRepo(path)
 		if err != nil {
 			return err
 		}
-		return newBranchCreatedMsg{}
+		return repoSwitchedMsg{path: root}
+	}
+}
+
+func createNewBranchWorktreeCmd(branchName string) tea.Cmd {
+	return func() tea.Msg {
+		return newBranchCreatingMsg{branchName: branchName}
 	}
 }
 
-func getWorktrees() ([]Worktree, error) {
-	cmd := exec.Command("git", "worktree", "list", "--porcelain")
+func performCreateNewBranchWorktreeCmd(repo, branchName string, opts createOptions) tea.Cmd {
+	return func() tea.Msg {
+		path, err := createNewBranchWorktree(repo, branchName, opts)
+		return resultMsg(newBranchCreatedMsg{path: path}, err)
+	}
+}
+
+func getWorktrees(repo string) ([]Worktree, error) {
+	cmd := exec.Command("git", "-C", repo, "worktree", "list", "--porcelain")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -124,16 +180,82 @@ func getWorktrees() ([]Worktree, error) {
 		worktrees = append(worktrees, currentWorktree)
 	}
 
+	annotateWorktrees(repo, worktrees)
+
 	return worktrees, nil
 }
 
-func getBranches() ([]Branch, error) {
-	localBranches, err := getLocalBranches()
+// annotateWorktrees fills in the upstream, last commit author and stored
+// metadata of each worktree, and the tag of detached ones. The dirty state
+// takes a git status per worktree, so the TUI loads it in the background.
+// Failures leave the fields empty rather than failing the whole listing.
+func annotateWorktrees(repo string, worktrees []Worktree) {
+	type refInfo struct{ upstream, author string }
+	refs := make(map[string]refInfo)
+
+	cmd := exec.Command("git", "-C", repo, "for-each-ref", "--format=%(refname:short)|%(upstream:short)|%(authorname)", "refs/heads/")
+	if output, err := cmd.Output(); err == nil {
+		for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+			parts := strings.SplitN(line, "|", 3)
+			if len(parts) == 3 {
+				refs[parts[0]] = refInfo{upstream: parts[1], author: parts[2]}
+			}
+		}
+	}
+
+	metadata, _ := loadMetadata(repo)
+	var tags map[string]string
+	for i := range worktrees {
+		worktrees[i].Meta = metadata[worktrees[i].Path]
+		if worktrees[i].Branch == "" && worktrees[i].Head != "" {
+			if tags == nil {
+				tags = getTagsByCommit(repo)
+			}
+			worktrees[i].Tag = tags[worktrees[i].Head]
+		}
+		if info, ok := refs[worktrees[i].Branch]; ok {
+			worktrees[i].Upstream = info.upstream
+			worktrees[i].Author = info.author
+		}
+	}
+}
+
+func isWorktreeDirty(path string) bool {
+	cmd := exec.Command("git", "-C", path, "status", "--porcelain")
+	output, err := cmd.Output()
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(output)) != ""
+}
+
+type worktreeDirtyMsg struct {
+	path  string
+	dirty bool
+}
+
+// refreshDirty checks every worktree for uncommitted changes in the
+// background, on every refresh since edits don't change the repository.
+// Until the answer arrives a worktree keeps its previous state.
+func (m *model) refreshDirty() tea.Cmd {
+	var cmds []tea.Cmd
+	for i, wt := range m.allWorktrees {
+		path := wt.Path
+		m.allWorktrees[i].Dirty, _ = m.dirty.get(path)
+		cmds = append(cmds, m.dirty.reload(path, inRepo(m.repo, func() tea.Msg {
+			return worktreeDirtyMsg{path: path, dirty: isWorktreeDirty(path)}
+		})))
+	}
+	return tea.Batch(cmds...)
+}
+
+func getBranches(repo string) ([]Branch, error) {
+	localBranches, err := getLocalBranches(repo)
 	if err != nil {
 		return nil, err
 	}
 
-	remoteBranches, err := getRemoteBranches()
+	remoteBranches, err := getRemoteBranches(repo)
 	if err != nil {
 		return nil, err
 	}
@@ -142,9 +264,11 @@ func getBranches() ([]Branch, error) {
 	allBranches = append(allBranches, localBranches...)
 	allBranches = append(allBranches, remoteBranches...)
 
+	// Local branches first, then remote branches
+	typeOrder := map[string]int{"local": 0, "remote": 1}
 	sort.Slice(allBranches, func(i, j int) bool {
 		if allBranches[i].Type != allBranches[j].Type {
-			return allBranches[i].Type == "local"
+			return typeOrder[allBranches[i].Type] < typeOrder[allBranches[j].Type]
 		}
 		return allBranches[i].LastCommit > allBranches[j].LastCommit
 	})
@@ -152,8 +276,8 @@ func getBranches() ([]Branch, error) {
 	return allBranches, nil
 }
 
-func getLocalBranches() ([]Branch, error) {
-	cmd := exec.Command("git", "for-each-ref", "--format=%(refname:short)|%(committerdate:iso8601)", "refs/heads/")
+func getLocalBranches(repo string) ([]Branch, error) {
+	cmd := exec.Command("git", "-C", repo, "for-each-ref", "--format=%(refname:short)|%(committerdate:iso8601)|%(authorname)", "refs/heads/")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -166,13 +290,14 @@ func getLocalBranches() ([]Branch, error) {
 		if line == "" {
 			continue
 		}
-		parts := strings.Split(line, "|")
-		if len(parts) == 2 {
+		parts := strings.SplitN(line, "|", 3)
+		if len(parts) == 3 {
 			lastCommit, _ := time.Parse("2006-01-02 15:04:05 -0700", parts[1])
 			branches = append(branches, Branch{
 				Name:     parts[0],
 				Type:     "local",
 				LastCommit: lastCommit.Format("2006-01-02 15:04:05"),
+				Author:   parts[2],
 			})
 		}
 	}
@@ -180,8 +305,8 @@ func getLocalBranches() ([]Branch, error) {
 	return branches, nil
 }
 
-func getRemoteBranches() ([]Branch, error) {
-	cmd := exec.Command("git", "for-each-ref", "--format=%(refname:short)|%(committerdate:iso8601)", "refs/remotes/")
+func getRemoteBranches(repo string) ([]Branch, error) {
+	cmd := exec.Command("git", "-C", repo, "for-each-ref", "--format=%(refname:short)|%(committerdate:iso8601)|%(authorname)", "refs/remotes/")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -194,13 +319,14 @@ func getRemoteBranches() ([]Branch, error) {
 		if line == "" {
 			continue
 		}
-		parts := strings.Split(line, "|")
-		if len(parts) == 2 {
+		parts := strings.SplitN(line, "|", 3)
+		if len(parts) == 3 {
 			lastCommit, _ := time.Parse("2006-01-02 15:04:05 -0700", parts[1])
 			branches = append(branches, Branch{
 				Name:     parts[0],
 				Type:     "remote",
 				LastCommit: lastCommit.Format("2006-01-02 15:04:05"),
+				Author:   parts[2],
 			})
 		}
 	}
@@ -208,47 +334,181 @@ func getRemoteBranches() ([]Branch, error) {
 	return branches, nil
 }
 
-func createWorktree(branch Branch) error {
-	repoName, err := getRepoName()
+// createOptions are the settings a new worktree is created with. The zero
+// value is a plain `git worktree add`.
+type createOptions struct {
+	sparse     SparseProfile
+	skipSmudge bool // leave Git LFS files as pointers
+}
+
+// newCreateOptions resolves the options for repo from the config, with the
+// named sparse profile ("" for a full checkout).
+func newCreateOptions(repo string, cfg Config, sparseName string) (createOptions, error) {
+	var opts createOptions
+	lfs, err := loadLFSConfig(repo, cfg)
 	if err != nil {
-		return err
+		return opts, err
 	}
-	
-	repoRoot, err := getRepoRoot()
+	opts.skipSmudge = lfs.SkipSmudge
+
+	sparse, err := loadSparseConfig(repo, cfg)
 	if err != nil {
+		return opts, err
+	}
+	opts.sparse, err = sparse.profile(sparseName)
+	return opts, err
+}
+
+// worktreeOptions is newCreateOptions for the TUI. Callers show its error
+// in the status line rather than create the worktree with other options.
+func (m model) worktreeOptions(sparseName string) (createOptions, error) {
+	return newCreateOptions(m.repo, m.config, sparseName)
+}
+
+// addWorktree runs `git worktree add [options] path commitish`. With a
+// sparse profile nothing is checked out up front; the cone is set first so
+// only its directories are ever written.
+func addWorktree(repo, path, commitish string, opts createOptions, options ...string) error {
+	var env []string
+	if opts.skipSmudge {
+		env = append(env, "GIT_LFS_SKIP_SMUDGE=1")
+	}
+	args := append([]string{"worktree", "add"}, options...)
+	if opts.sparse.Name != "" {
+		args = append(args, "--no-checkout")
+	}
+	if _, err := gitInEnv(repo, env, append(args, path, commitish)...); err != nil {
 		return err
 	}
+	if opts.sparse.Name == "" {
+		return nil
+	}
+
+	err := setSparseProfile(path, opts.sparse)
+	if err == nil {
+		_, err = gitInEnv(path, env, "checkout")
+	}
+	if err != nil {
+		gitIn(repo, "worktree", "remove", "--force", path)
+		return fmt.Errorf("sparse checkout of '%s' failed: %w", opts.sparse.Name, err)
+	}
+	return nil
+}
+
+// setupWorktree runs the configured post-create steps in the new worktree
+// at path: submodules first, since they can use LFS too. It returns a
+// description of each step that ran.
+func setupWorktree(cfg Config, path string) ([]string, error) {
+	var steps []string
+	submodules, err := loadSubmoduleConfig(path, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if submodules.Init && hasSubmodules(path) {
+		if err := initSubmodules(submodules, path); err != nil {
+			return steps, fmt.Errorf("submodules: %w", err)
+		}
+		steps = append(steps, "initialized submodules")
+	}
+
+	lfs, err := loadLFSConfig(path, cfg)
+	if err != nil {
+		return steps, err
+	}
+	if lfs.SkipSmudge && len(lfs.Include) > 0 && usesLFS(path) {
+		if err := lfsPull(path, lfs.Include); err != nil {
+			return steps, err
+		}
+		steps = append(steps, "pulled LFS files matching "+strings.Join(lfs.Include, ","))
+	}
+	return steps, nil
+}
+
+type worktreeSetUpMsg struct {
+	path  string
+	steps []string
+}
+
+// setupWorktreeCmd runs setupWorktree for a worktree the TUI just created.
+func (m model) setupWorktreeCmd(path string) tea.Cmd {
+	cfg := m.config
+	return func() tea.Msg {
+		steps, err := setupWorktree(cfg, path)
+		if err != nil {
+			return fmt.Errorf("setting up %s: %w", filepath.Base(path), err)
+		}
+		if len(steps) == 0 {
+			return nil
+		}
+		return worktreeSetUpMsg{path: path, steps: steps}
+	}
+}
+
+// createWorktree adds a worktree for branch and returns its path.
+func createWorktree(repo string, branch Branch, opts createOptions) (string, error) {
+	if branch.Type == "tag" {
+		return createDetachedWorktree(repo, "refs/tags/"+branch.Name, branch.Name, opts)
+	}
 	
-	parentDir := filepath.Dir(repoRoot)
-	branchName := strings.ReplaceAll(branch.Name, "/", "-")
-	worktreePath := filepath.Join(parentDir, repoName + "-" + branchName)
+	worktreePath, err := worktreePathFor(repo, branch.Name)
+	if err != nil {
+		return "", err
+	}
 	
-	var cmd *exec.Cmd
-	if branch.Type == "local" {
-		cmd = exec.Command("git", "worktree", "add", worktreePath, branch.Name)
-	} else {
+	var options []string
+	if branch.Type != "local" {
 		localBranchName := strings.TrimPrefix(branch.Name, "origin/")
-		cmd = exec.Command("git", "worktree", "add", "-b", localBranchName, worktreePath, branch.Name)
+		options = []string{"-b", localBranchName}
 	}
 	
-	return cmd.Run()
+	if err := addWorktree(repo, worktreePath, branch.Name, opts, options...); err != nil {
+		return "", err
+	}
+	// The worktree exists either way, so a metadata error comes with its path
+	return worktreePath, recordCreated(worktreePath)
+}
+
+// worktreePathFor returns where the worktree for branchName is created: a
+// sibling of the repository named <repo>-<branch>, with slashes replaced.
+func worktreePathFor(repo, branchName string) (string, error) {
+	repoRoot, err := getRepoRoot(repo)
+	if err != nil {
+		return "", err
+	}
+	return worktreePathIn(repoRoot, branchName), nil
 }
 
-func deleteWorktree(worktree Worktree) error {
-	cmd := exec.Command("git", "worktree", "remove", worktree.Path)
-	return cmd.Run()
+// worktreePathIn is worktreePathFor for a known repository root.
+func worktreePathIn(repoRoot, branchName string) string {
+	parentDir := filepath.Dir(repoRoot)
+	sanitizedBranchName := strings.ReplaceAll(branchName, "/", "-")
+	return filepath.Join(parentDir, filepath.Base(repoRoot) + "-" + sanitizedBranchName)
+}
+
+// deleteWorktree removes the worktree and forgets its metadata.
+func deleteWorktree(repo string, worktree Worktree) error {
+	cmd := exec.Command("git", "-C", repo, "worktree", "remove", worktree.Path)
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+	if err := forgetWorktreeMeta(repo, worktree.Path); err != nil {
+		return metadataError{err}
+	}
+	return nil
 }
 
-func getRepoName() (string, error) {
-	repoRoot, err := getRepoRoot()
+func getRepoName(repo string) (string, error) {
+	repoRoot, err := getRepoRoot(repo)
 	if err != nil {
 		return "", err
 	}
 	return filepath.Base(repoRoot), nil
 }
 
-func getRepoRoot() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+// getGitCommonDir returns the absolute path of the git directory shared by
+// all worktrees of the repository.
+func getGitCommonDir(repo string) (string, error) {
+	cmd := exec.Command("git", "-C", repo, "rev-parse", "--path-format=absolute", "--git-common-dir")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", err
@@ -257,46 +517,57 @@ func getRepoRoot() (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
-func createNewBranchWorktree(branchName string) error {
-	repoName, err := getRepoName()
+// getRepoRoot returns the top directory of the worktree repo is in.
+func getRepoRoot(repo string) (string, error) {
+	cmd := exec.Command("git", "-C", repo, "rev-parse", "--show-toplevel")
+	output, err := cmd.Output()
 	if err != nil {
-		return err
+		return "", err
 	}
 	
-	repoRoot, err := getRepoRoot()
+	return strings.TrimSpace(string(output)), nil
+}
+
+// createNewBranchWorktree creates branchName from the origin main branch in
+// a new worktree and returns the worktree path.
+func createNewBranchWorktree(repo, branchName string, opts createOptions) (string, error) {
+	// Find the main branch from origin (origin/main or origin/master)
+	mainBranch, err := getOriginMainBranch(repo)
 	if err != nil {
-		return err
+		return "", err
 	}
-	
-	// Find the main branch from origin (origin/main or origin/master)
-	mainBranch, err := getOriginMainBranch()
+	return createNewBranchWorktreeFrom(repo, branchName, mainBranch, opts)
+}
+
+// createNewBranchWorktreeFrom creates branchName from base in a new
+// worktree and returns the worktree path.
+func createNewBranchWorktreeFrom(repo, branchName, base string, opts createOptions) (string, error) {
+	worktreePath, err := worktreePathFor(repo, branchName)
 	if err != nil {
-		return err
+		return "", err
 	}
 	
-	parentDir := filepath.Dir(repoRoot)
-	sanitizedBranchName := strings.ReplaceAll(branchName, "/", "-")
-	worktreePath := filepath.Join(parentDir, repoName + "-" + sanitizedBranchName)
-	
-	cmd := exec.Command("git", "worktree", "add", "--no-track", "-b", branchName, worktreePath, mainBranch)
-	return cmd.Run()
+	if err := addWorktree(repo, worktreePath, base, opts, "--no-track", "-b", branchName); err != nil {
+		return "", err
+	}
+	return worktreePath, recordCreated(worktreePath)
 }
 
-func getOriginMainBranch() (string, error) {
+func getOriginMainBranch(repo string) (string, error) {
 	// Try origin/main first
-	cmd := exec.Command("git", "rev-parse", "--verify", "origin/main")
+	cmd := exec.Command("git", "-C", repo, "rev-parse", "--verify", "origin/main")
 	if err := cmd.Run(); err == nil {
 		return "origin/main", nil
 	}
 	
 	// Fall back to origin/master
-	cmd = exec.Command("git", "rev-parse", "--verify", "origin/master")
+	cmd = exec.Command("git", "-C", repo, "rev-parse", "--verify", "origin/master")
 	if err := cmd.Run(); err == nil {
 		return "origin/master", nil
 	}
 	
 	// If neither exists, try to find the default remote branch
-	cmd = exec.Command("git", "symbolic-ref", "refs/remotes/origin/HEAD")
+	cmd = exec.Command("git", "-C", repo, "symbolic-ref", "refs/remotes/origin/HEAD")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("could not find origin main branch (tried origin/main, origin/master)")
@@ -311,12 +582,100 @@ func getOriginMainBranch() (string, error) {
 	return "", fmt.Errorf("could not parse origin main branch reference")
 }
 
+// openWorktree opens the worktree in Cursor and records when it was
+// opened.
 func openWorktree(worktree Worktree) error {
 	cmd := exec.Command("cursor", worktree.Path)
-	return cmd.Run()
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+	return markOpened(worktree.Path)
+}
+
+// openWorktreeWith runs a user-configured opener such as "code -n" with
+// the worktree path as its last argument.
+func openWorktreeWith(worktree Worktree, command string) error {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return fmt.Errorf("empty open command")
+	}
+	cmd := exec.Command(fields[0], append(fields[1:], worktree.Path)...)
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+	return markOpened(worktree.Path)
+}
+
+// pruneWorktrees prunes stale worktree administrative files along with
+// the metadata of worktrees that no longer exist.
+func pruneWorktrees(repo string) error {
+	cmd := exec.Command("git", "-C", repo, "worktree", "prune")
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+	worktrees, err := getWorktrees(repo)
+	if err != nil {
+		return err
+	}
+	return pruneMetadata(repo, worktrees)
+}
+
+// runHook runs a user-configured shell command inside the worktree.
+func runHook(worktree Worktree, command string) error {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = worktree.Path
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// resolveRepo returns the top directory of the repository worktree at
+// path, which later git commands run in. The process working directory
+// never changes, so commands still running for the previous repository
+// aren't affected.
+func resolveRepo(path string) (string, error) {
+	path = expandHome(path)
+	if !isGitRepository(path) {
+		return "", fmt.Errorf("%s is not a git repository", path)
+	}
+	return getRepoRoot(path)
+}
+
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
 }
 
-func isGitRepository() bool {
-	cmd := exec.Command("git", "rev-parse", "--git-dir")
+func isGitRepository(dir string) bool {
+	cmd := exec.Command("git", "-C", dir, "rev-parse", "--git-dir")
 	return cmd.Run() == nil
-}
\ No newline at end of file
+}
+
+// gitIn runs git in dir and returns its trimmed combined output, wrapping
+// it into the error on failure.
+func gitIn(dir string, args ...string) (string, error) {
+	return gitInEnv(dir, nil, args...)
+}
+
+// gitInEnv is gitIn with extra environment variables.
+func gitInEnv(dir string, env []string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	output, err := cmd.CombinedOutput()
+	out := strings.TrimSpace(string(output))
+	if err != nil {
+		return out, fmt.Errorf("git %s: %w: %s", args[0], err, out)
+	}
+	return out, nil
+}
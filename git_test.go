@@ -2,6 +2,10 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -94,7 +98,7 @@ func TestParseWorktreeLine(t *testing.T) {
 			if err != nil {
 				t.Errorf("Unexpected error for input %q: %v", test.input, err)
 			}
-			if result != test.expected {
+			if !reflect.DeepEqual(result, test.expected) {
 				t.Errorf("parseWorktreeLine(%q) = %v, expected %v", test.input, result, test.expected)
 			}
 		}
@@ -145,4 +149,87 @@ func TestGitError(t *testing.T) {
 }
 */
 
-// Note: sanitizeBranchName function doesn't exist in current codebase
\ No newline at end of file
+// Note: sanitizeBranchName function doesn't exist in current codebase
+
+// requireGit skips tests that shell out to git when it isn't installed and
+// gives commits a fixed identity.
+func requireGit(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "Test User")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test User")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// newTestRepo creates a bare "origin" with one commit on main and a clone
+// of it named "repo", and returns the clone's path.
+func newTestRepo(t *testing.T) string {
+	t.Helper()
+	requireGit(t)
+
+	root := t.TempDir()
+	origin := filepath.Join(root, "origin.git")
+	repo := filepath.Join(root, "repo")
+
+	runGit(t, root, "init", "--bare", "-b", "main", origin)
+	runGit(t, root, "clone", origin, repo)
+	runGit(t, repo, "checkout", "-b", "main")
+	if err := os.WriteFile(filepath.Join(repo, "README"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repo, "add", "README")
+	runGit(t, repo, "commit", "-m", "initial")
+	runGit(t, repo, "push", "-u", "origin", "main")
+	return repo
+}
+
+func TestSwitchRepoKeepsWorkingDirectory(t *testing.T) {
+	first := newTestRepo(t)
+	second := newTestRepo(t)
+	t.Chdir(first)
+
+	msg := switchRepoCmd(filepath.Join(second, "."))()
+	switched, ok := msg.(repoSwitchedMsg)
+	if !ok || switched.path != second {
+		t.Fatalf("Expected a switch to %s, got %v", second, msg)
+	}
+	if wd, _ := os.Getwd(); wd != first {
+		t.Errorf("Expected the working directory to stay %s, got %s", first, wd)
+	}
+	if err, ok := switchRepoCmd(t.TempDir())().(error); !ok || !strings.Contains(err.Error(), "not a git repository") {
+		t.Errorf("Expected an error for a directory outside git, got %v", err)
+	}
+
+	m := initialModel()
+	stale := getWorktreesCmd(m.repo)
+	newModel, _ := m.Update(switched)
+	m = newModel.(model)
+
+	// Worktrees listed for the previous repository arrive too late
+	newModel, _ = m.Update(stale())
+	m = newModel.(model)
+	if len(m.allWorktrees) != 0 {
+		t.Errorf("Expected the previous repository's worktrees to be dropped, got %v", m.allWorktrees)
+	}
+
+	newModel, _ = m.Update(getWorktreesCmd(m.repo)())
+	m = newModel.(model)
+	if len(m.allWorktrees) != 1 || m.allWorktrees[0].Path != second {
+		t.Errorf("Expected the worktree of %s, got %v", second, m.allWorktrees)
+	}
+}
@@ -0,0 +1,449 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// keyMap holds every key binding used by the TUI. Both the Update loop and
+// the help text are driven from it so they can never disagree.
+type keyMap struct {
+	Up          key.Binding
+	Down        key.Binding
+	Top         key.Binding
+	Bottom      key.Binding
+	Select      key.Binding
+	SwitchView  key.Binding
+	Filter      key.Binding
+	NewBranch   key.Binding
+	Delete      key.Binding
+	Help        key.Binding
+	Palette     key.Binding
+	Fetch       key.Binding
+	Pull        key.Binding
+	Push        key.Binding
+	PullRequest key.Binding
+	// CheckoutCommit creates a detached worktree for a commit or tag.
+	CheckoutCommit key.Binding
+	Scratch        key.Binding
+	Edit           key.Binding
+	Note           key.Binding
+	Pin            key.Binding
+	Sparse         key.Binding
+	MoveChanges    key.Binding
+	Promote        key.Binding
+	Apply          key.Binding
+	Pop            key.Binding
+	Mark           key.Binding
+	Clean          key.Binding
+	Quit           key.Binding
+	ForceQuit      key.Binding
+
+	// Bindings that stay active while a text input has focus, so they
+	// must not use plain printable characters.
+	Confirm   key.Binding
+	Cancel    key.Binding
+	InputUp   key.Binding
+	InputDown key.Binding
+	Save      key.Binding
+	// Untracked includes or leaves out untracked files when moving
+	// changes.
+	Untracked key.Binding
+}
+
+// helpKeys is the set of bindings shown for one mode. It implements
+// help.KeyMap so it can be rendered by the bubbles help component.
+type helpKeys struct {
+	short []key.Binding
+	full  [][]key.Binding
+}
+
+func (h helpKeys) ShortHelp() []key.Binding  { return h.short }
+func (h helpKeys) FullHelp() [][]key.Binding { return h.full }
+
+func defaultKeyMap() keyMap {
+	return keyMap{
+		Up:             key.NewBinding(key.WithKeys("up", "k")),
+		Down:           key.NewBinding(key.WithKeys("down", "j")),
+		Top:            key.NewBinding(key.WithKeys("home")),
+		Bottom:         key.NewBinding(key.WithKeys("end")),
+		Select:         key.NewBinding(key.WithKeys("enter")),
+		SwitchView:     key.NewBinding(key.WithKeys("tab")),
+		Filter:         key.NewBinding(key.WithKeys("/", "f")),
+		NewBranch:      key.NewBinding(key.WithKeys("n")),
+		Delete:         key.NewBinding(key.WithKeys("d")),
+		Help:           key.NewBinding(key.WithKeys("?")),
+		Palette:        key.NewBinding(key.WithKeys("ctrl+p")),
+		Fetch:          key.NewBinding(key.WithKeys("F")),
+		Pull:           key.NewBinding(key.WithKeys("p")),
+		Push:           key.NewBinding(key.WithKeys("P")),
+		PullRequest:    key.NewBinding(key.WithKeys("r")),
+		CheckoutCommit: key.NewBinding(key.WithKeys("c")),
+		Scratch:        key.NewBinding(key.WithKeys("s")),
+		Edit:           key.NewBinding(key.WithKeys("e")),
+		Note:           key.NewBinding(key.WithKeys("N")),
+		Pin:            key.NewBinding(key.WithKeys("*")),
+		Sparse:         key.NewBinding(key.WithKeys("S")),
+		MoveChanges:    key.NewBinding(key.WithKeys("M")),
+		Promote:        key.NewBinding(key.WithKeys("b")),
+		Apply:          key.NewBinding(key.WithKeys("a")),
+		Pop:            key.NewBinding(key.WithKeys("p")),
+		Mark:           key.NewBinding(key.WithKeys(" ")),
+		Clean:          key.NewBinding(key.WithKeys("X")),
+		Quit:           key.NewBinding(key.WithKeys("q")),
+		ForceQuit:      key.NewBinding(key.WithKeys("ctrl+c")),
+		Confirm:        key.NewBinding(key.WithKeys("enter")),
+		Cancel:         key.NewBinding(key.WithKeys("esc")),
+		InputUp:        key.NewBinding(key.WithKeys("up")),
+		InputDown:      key.NewBinding(key.WithKeys("down")),
+		Save:           key.NewBinding(key.WithKeys("ctrl+s")),
+		Untracked:      key.NewBinding(key.WithKeys("ctrl+t")),
+	}
+}
+
+// keyPresets adjust the default key map for users coming from other editors.
+var keyPresets = map[string]func(*keyMap){
+	"default": func(*keyMap) {},
+	"vim": func(k *keyMap) {
+		k.Top.SetKeys("g", "home")
+		k.Bottom.SetKeys("G", "end")
+		k.Delete.SetKeys("d", "x")
+		k.Palette.SetKeys(":", "ctrl+p")
+		k.InputUp.SetKeys("up", "ctrl+k")
+		k.InputDown.SetKeys("down", "ctrl+j")
+	},
+	"emacs": func(k *keyMap) {
+		k.Up.SetKeys("up", "ctrl+p")
+		k.Down.SetKeys("down", "ctrl+n")
+		k.Top.SetKeys("alt+<", "home")
+		k.Bottom.SetKeys("alt+>", "end")
+		k.Filter.SetKeys("/", "ctrl+s")
+		k.Palette.SetKeys("alt+x")
+		k.Cancel.SetKeys("esc", "ctrl+g")
+		k.InputUp.SetKeys("up", "ctrl+p")
+		k.InputDown.SetKeys("down", "ctrl+n")
+	},
+}
+
+// actions maps the action names used in the config file to bindings.
+func (k *keyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":              &k.Up,
+		"down":            &k.Down,
+		"top":             &k.Top,
+		"bottom":          &k.Bottom,
+		"select":          &k.Select,
+		"switch_view":     &k.SwitchView,
+		"filter":          &k.Filter,
+		"new_branch":      &k.NewBranch,
+		"delete":          &k.Delete,
+		"help":            &k.Help,
+		"palette":         &k.Palette,
+		"fetch":           &k.Fetch,
+		"pull":            &k.Pull,
+		"push":            &k.Push,
+		"pull_request":    &k.PullRequest,
+		"checkout_commit": &k.CheckoutCommit,
+		"scratch":         &k.Scratch,
+		"edit":            &k.Edit,
+		"note":            &k.Note,
+		"pin":             &k.Pin,
+		"sparse":          &k.Sparse,
+		"move_changes":    &k.MoveChanges,
+		"promote":         &k.Promote,
+		"apply":           &k.Apply,
+		"pop":             &k.Pop,
+		"mark":            &k.Mark,
+		"clean":           &k.Clean,
+		"quit":            &k.Quit,
+		"force_quit":      &k.ForceQuit,
+		"confirm":         &k.Confirm,
+		"cancel":          &k.Cancel,
+		"input_up":        &k.InputUp,
+		"input_down":      &k.InputDown,
+		"save":            &k.Save,
+		"untracked":       &k.Untracked,
+	}
+}
+
+// newKeyMap builds the key map for a preset and applies per-action
+// overrides from the config.
+func newKeyMap(cfg KeysConfig) (keyMap, error) {
+	keys := defaultKeyMap()
+
+	preset := cfg.Preset
+	if preset == "" {
+		preset = "default"
+	}
+	apply, ok := keyPresets[preset]
+	if !ok {
+		return keys, fmt.Errorf("unknown key preset %q (expected default, vim or emacs)", preset)
+	}
+	apply(&keys)
+
+	actions := keys.actions()
+	for name, bound := range cfg.Bindings {
+		binding, ok := actions[name]
+		if !ok {
+			return keys, fmt.Errorf("unknown key binding action %q", name)
+		}
+		if len(bound) == 0 {
+			binding.Unbind()
+			continue
+		}
+		binding.SetKeys(bound...)
+	}
+
+	return keys, nil
+}
+
+var keyLabels = map[string]string{
+	"up":    "↑",
+	"down":  "↓",
+	"left":  "←",
+	"right": "→",
+	" ":     "space",
+}
+
+// withHelp returns a copy of b labelled for the help view.
+func withHelp(b key.Binding, desc string) key.Binding {
+	var labels []string
+	for _, k := range b.Keys() {
+		if label, ok := keyLabels[k]; ok {
+			k = label
+		}
+		labels = append(labels, k)
+	}
+	b.SetHelp(strings.Join(labels, "/"), desc)
+	return b
+}
+
+// helpKeys returns the bindings that apply in the model's current mode.
+func (m model) helpKeys() helpKeys {
+	k := m.keys
+	quit := withHelp(k.Quit, "quit")
+	help := withHelp(k.Help, "toggle help")
+
+	switch {
+	case m.enteringPR, m.enteringCommit:
+		confirm := withHelp(k.Confirm, "create worktree")
+		cancel := withHelp(k.Cancel, "cancel")
+		return helpKeys{
+			short: []key.Binding{confirm, cancel},
+			full: [][]key.Binding{
+				{confirm, cancel},
+				{withHelp(k.ForceQuit, "force quit")},
+			},
+		}
+	case m.movingChanges:
+		confirm := withHelp(k.Confirm, "move")
+		cancel := withHelp(k.Cancel, "cancel")
+		prev := withHelp(k.InputUp, "prev worktree")
+		next := withHelp(k.InputDown, "next worktree")
+		untracked := withHelp(k.Untracked, "untracked files")
+		return helpKeys{
+			short: []key.Binding{confirm, prev, next, untracked, cancel},
+			full: [][]key.Binding{
+				{prev, next, untracked},
+				{confirm, cancel},
+				{withHelp(k.ForceQuit, "force quit")},
+			},
+		}
+	case m.confirmingClean:
+		confirm := withHelp(k.Confirm, "remove ignored files")
+		cancel := withHelp(k.Cancel, "cancel")
+		return helpKeys{
+			short: []key.Binding{confirm, cancel},
+			full: [][]key.Binding{
+				{confirm, cancel},
+				{withHelp(k.ForceQuit, "force quit")},
+			},
+		}
+	case m.confirmingDrop:
+		confirm := withHelp(k.Confirm, "drop stash")
+		cancel := withHelp(k.Cancel, "cancel")
+		return helpKeys{
+			short: []key.Binding{confirm, cancel},
+			full: [][]key.Binding{
+				{confirm, cancel},
+				{withHelp(k.ForceQuit, "force quit")},
+			},
+		}
+	case m.choosingSparse:
+		confirm := withHelp(k.Confirm, "use profile")
+		cancel := withHelp(k.Cancel, "cancel")
+		prev := withHelp(k.InputUp, "prev profile")
+		next := withHelp(k.InputDown, "next profile")
+		return helpKeys{
+			short: []key.Binding{confirm, prev, next, cancel},
+			full: [][]key.Binding{
+				{prev, next},
+				{confirm, cancel},
+				{withHelp(k.ForceQuit, "force quit")},
+			},
+		}
+	case m.applyingStash:
+		confirm := withHelp(k.Confirm, "apply")
+		if m.stashPop {
+			confirm = withHelp(k.Confirm, "pop")
+		}
+		cancel := withHelp(k.Cancel, "cancel")
+		prev := withHelp(k.InputUp, "prev worktree")
+		next := withHelp(k.InputDown, "next worktree")
+		return helpKeys{
+			short: []key.Binding{confirm, prev, next, cancel},
+			full: [][]key.Binding{
+				{prev, next},
+				{confirm, cancel},
+				{withHelp(k.ForceQuit, "force quit")},
+			},
+		}
+	case m.editingNote:
+		save := withHelp(k.Save, "save note")
+		cancel := withHelp(k.Cancel, "cancel")
+		return helpKeys{
+			short: []key.Binding{save, cancel},
+			full: [][]key.Binding{
+				{save, cancel},
+				{withHelp(k.ForceQuit, "force quit")},
+			},
+		}
+	case m.editingMeta:
+		confirm := withHelp(k.Confirm, "save")
+		cancel := withHelp(k.Cancel, "cancel")
+		prev := withHelp(k.InputUp, "prev field")
+		next := withHelp(k.InputDown, "next field")
+		return helpKeys{
+			short: []key.Binding{confirm, prev, next, cancel},
+			full: [][]key.Binding{
+				{prev, next},
+				{confirm, cancel},
+				{withHelp(k.ForceQuit, "force quit")},
+			},
+		}
+	case m.paletteOpen:
+		confirm := withHelp(k.Confirm, "run")
+		cancel := withHelp(k.Cancel, "close")
+		up := withHelp(k.InputUp, "up")
+		down := withHelp(k.InputDown, "down")
+		return helpKeys{
+			short: []key.Binding{confirm, up, down, cancel},
+			full: [][]key.Binding{
+				{up, down},
+				{confirm, cancel},
+				{withHelp(k.ForceQuit, "force quit")},
+			},
+		}
+	case m.creatingBranch:
+		confirm := withHelp(k.Confirm, "create")
+		cancel := withHelp(k.Cancel, "cancel")
+		if len(m.newBranchRules.Prefixes) > 0 {
+			prev := withHelp(k.InputUp, "prev prefix")
+			next := withHelp(k.InputDown, "next prefix")
+			return helpKeys{
+				short: []key.Binding{confirm, prev, next, cancel},
+				full: [][]key.Binding{
+					{prev, next},
+					{confirm, cancel},
+					{withHelp(k.ForceQuit, "force quit")},
+				},
+			}
+		}
+		return helpKeys{
+			short: []key.Binding{confirm, cancel},
+			full: [][]key.Binding{
+				{confirm, cancel},
+				{withHelp(k.ForceQuit, "force quit")},
+			},
+		}
+	case m.filtering:
+		confirm := withHelp(k.Confirm, "select")
+		if m.view == "worktrees" {
+			confirm = withHelp(k.Confirm, "open")
+		} else if m.view == "stashes" {
+			confirm = withHelp(k.Confirm, "apply")
+		}
+		cancel := withHelp(k.Cancel, "clear filter")
+		up := withHelp(k.InputUp, "up")
+		down := withHelp(k.InputDown, "down")
+		return helpKeys{
+			short: []key.Binding{confirm, up, down, cancel},
+			full: [][]key.Binding{
+				{up, down},
+				{confirm, cancel},
+				{withHelp(k.ForceQuit, "force quit")},
+			},
+		}
+	}
+
+	nav := []key.Binding{
+		withHelp(k.Up, "up"),
+		withHelp(k.Down, "down"),
+		withHelp(k.Top, "top"),
+		withHelp(k.Bottom, "bottom"),
+	}
+	filter := withHelp(k.Filter, "filter")
+	palette := withHelp(k.Palette, "commands")
+	fetch := withHelp(k.Fetch, "fetch")
+	pr := withHelp(k.PullRequest, "PR worktree")
+	commit := withHelp(k.CheckoutCommit, "commit worktree")
+
+	if m.view == "worktrees" {
+		open := withHelp(k.Select, "open")
+		del := withHelp(k.Delete, "delete")
+		switchView := withHelp(k.SwitchView, nextView(m.view))
+		return helpKeys{
+			short: []key.Binding{open, del, filter, switchView, palette, help, quit},
+			full: [][]key.Binding{
+				nav,
+				{open, del, withHelp(k.Edit, "edit details"), withHelp(k.Note, "edit note"), withHelp(k.Pin, "pin"), withHelp(k.Sparse, "sparse profile"), filter, palette},
+				{fetch, withHelp(k.Pull, "pull"), withHelp(k.Push, "push"), withHelp(k.MoveChanges, "move changes"), withHelp(k.Promote, "changes to new branch")},
+				{withHelp(k.Mark, "mark"), withHelp(k.Clean, "clean ignored files")},
+				{pr, commit, withHelp(k.Scratch, "scratch worktree")},
+				{switchView, help, quit, withHelp(k.ForceQuit, "force quit")},
+			},
+		}
+	}
+
+	switchView := withHelp(k.SwitchView, nextView(m.view))
+	if m.view == "stashes" {
+		apply := withHelp(k.Select, "apply")
+		pop := withHelp(k.Pop, "pop")
+		drop := withHelp(k.Delete, "drop")
+		return helpKeys{
+			short: []key.Binding{apply, pop, drop, filter, switchView, palette, help, quit},
+			full: [][]key.Binding{
+				nav,
+				{apply, withHelp(k.Apply, "apply"), pop, drop, filter, palette},
+				{switchView, help, quit, withHelp(k.ForceQuit, "force quit")},
+			},
+		}
+	}
+
+	if m.view == "tags" {
+		create := withHelp(k.Select, "detached worktree")
+		return helpKeys{
+			short: []key.Binding{create, filter, switchView, palette, help, quit},
+			full: [][]key.Binding{
+				nav,
+				{create, withHelp(k.Sparse, "sparse profile"), filter, palette},
+				{fetch, pr, commit, withHelp(k.Scratch, "scratch worktree")},
+				{switchView, help, quit, withHelp(k.ForceQuit, "force quit")},
+			},
+		}
+	}
+
+	create := withHelp(k.Select, "create worktree")
+	newBranch := withHelp(k.NewBranch, "new branch")
+	return helpKeys{
+		short: []key.Binding{create, newBranch, filter, switchView, palette, help, quit},
+		full: [][]key.Binding{
+			nav,
+			{create, newBranch, withHelp(k.Sparse, "sparse profile"), filter, palette},
+			{fetch, pr, commit, withHelp(k.Scratch, "scratch worktree")},
+			{switchView, help, quit, withHelp(k.ForceQuit, "force quit")},
+		},
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestNewKeyMapPresets(t *testing.T) {
+	g := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'g'}}
+	ctrlN := tea.KeyMsg{Type: tea.KeyCtrlN}
+
+	keys, err := newKeyMap(KeysConfig{})
+	if err != nil {
+		t.Fatalf("Unexpected error for default preset: %v", err)
+	}
+	if key.Matches(g, keys.Top) {
+		t.Error("Expected 'g' to be unbound in the default preset")
+	}
+
+	keys, err = newKeyMap(KeysConfig{Preset: "vim"})
+	if err != nil {
+		t.Fatalf("Unexpected error for vim preset: %v", err)
+	}
+	if !key.Matches(g, keys.Top) {
+		t.Error("Expected 'g' to jump to top in the vim preset")
+	}
+
+	keys, err = newKeyMap(KeysConfig{Preset: "emacs"})
+	if err != nil {
+		t.Fatalf("Unexpected error for emacs preset: %v", err)
+	}
+	if !key.Matches(ctrlN, keys.Down) {
+		t.Error("Expected ctrl+n to move down in the emacs preset")
+	}
+
+	if _, err := newKeyMap(KeysConfig{Preset: "nano"}); err == nil {
+		t.Error("Expected error for unknown preset")
+	}
+}
+
+func TestNewKeyMapOverrides(t *testing.T) {
+	keys, err := newKeyMap(KeysConfig{Bindings: map[string][]string{
+		"delete": {"x"},
+		"filter": {},
+	}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !key.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}}, keys.Delete) {
+		t.Error("Expected 'x' to delete after override")
+	}
+	if key.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}}, keys.Delete) {
+		t.Error("Expected 'd' to no longer delete after override")
+	}
+	if keys.Filter.Enabled() {
+		t.Error("Expected an empty binding list to unbind the action")
+	}
+
+	if _, err := newKeyMap(KeysConfig{Bindings: map[string][]string{"explode": {"e"}}}); err == nil {
+		t.Error("Expected error for unknown action")
+	}
+}
+
+func TestHelpOverlay(t *testing.T) {
+	m := initialModel()
+	m.keys, _ = newKeyMap(KeysConfig{Bindings: map[string][]string{"delete": {"x"}}})
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'?'}})
+	m = newModel.(model)
+	if !m.showHelp {
+		t.Fatal("Expected '?' to open the help overlay")
+	}
+
+	view := m.View()
+	if !strings.Contains(view, "x") || !strings.Contains(view, "delete") {
+		t.Errorf("Expected overlay to list the overridden delete binding, got:\n%s", view)
+	}
+
+	// Keys other than help/cancel are swallowed by the overlay
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	m = newModel.(model)
+	if cmd != nil || !m.showHelp {
+		t.Error("Expected the overlay to swallow 'q'")
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = newModel.(model)
+	if m.showHelp {
+		t.Error("Expected esc to close the help overlay")
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// lfsPointersMaxAge is how long the pointer count of a worktree is shown
+// before a refresh counts again, since `git lfs pull` or a checkout outside
+// wtree changes it.
+const lfsPointersMaxAge = 30 * time.Second
+
+// LFSConfig controls how Git LFS files of new worktrees are fetched.
+type LFSConfig struct {
+	// SkipSmudge creates worktrees with pointer files instead of
+	// downloading every LFS object.
+	SkipSmudge bool `json:"skip_smudge"`
+	// Include lists the patterns pulled right after creation when
+	// SkipSmudge is set, e.g. "assets/icons/**". Everything else stays a
+	// pointer until pulled by hand.
+	Include []string `json:"include"`
+}
+
+// loadLFSConfig resolves the LFS settings of repo.
+func loadLFSConfig(repo string, cfg Config) (LFSConfig, error) {
+	if len(cfg.LFS) == 0 {
+		return LFSConfig{}, nil
+	}
+	repoRoot, err := getRepoRoot(repo)
+	if err != nil {
+		return LFSConfig{}, err
+	}
+	return repoEntry(cfg.LFS, repoRoot), nil
+}
+
+// usesLFS reports whether the worktree at path tracks files with Git LFS,
+// from its top-level .gitattributes.
+func usesLFS(path string) bool {
+	attributes, err := os.ReadFile(filepath.Join(path, ".gitattributes"))
+	return err == nil && strings.Contains(string(attributes), "filter=lfs")
+}
+
+var lfsInstalled = sync.OnceValue(func() bool {
+	return exec.Command("git", "lfs", "version").Run() == nil
+})
+
+// parseLFSFiles counts the files in `git lfs ls-files` output and those of
+// them that are still pointers, which are marked with "-" instead of "*".
+func parseLFSFiles(output string) (total, pointers int) {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		total++
+		if fields[1] == "-" {
+			pointers++
+		}
+	}
+	return total, pointers
+}
+
+// countLFSPointers counts the LFS files of the worktree at path that are
+// still pointers. It is 0 outside LFS repositories or without git-lfs.
+func countLFSPointers(path string) int {
+	if !usesLFS(path) || !lfsInstalled() {
+		return 0
+	}
+	output, err := gitIn(path, "lfs", "ls-files")
+	if err != nil {
+		return 0
+	}
+	_, pointers := parseLFSFiles(output)
+	return pointers
+}
+
+type lfsPointersMsg struct {
+	path     string
+	pointers int
+}
+
+// refreshLFSPointers counts the pointer files of the worktrees in the
+// background, skipping those counted recently.
+func (m *model) refreshLFSPointers() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, wt := range m.allWorktrees {
+		path := wt.Path
+		cmds = append(cmds, m.lfsPointers.load(path, inRepo(m.repo, func() tea.Msg {
+			return lfsPointersMsg{path: path, pointers: countLFSPointers(path)}
+		})))
+	}
+	return tea.Batch(cmds...)
+}
+
+func describeLFSPointers(pointers int) string {
+	if pointers == 1 {
+		return "LFS: 1 pointer file"
+	}
+	return fmt.Sprintf("LFS: %d pointer files", pointers)
+}
+
+// lfsPull downloads and checks out the LFS files of the worktree at path
+// that match include, or all of them when include is empty.
+func lfsPull(path string, include []string) error {
+	if !lfsInstalled() {
+		return fmt.Errorf("git-lfs is not installed")
+	}
+	args := []string{"lfs", "pull"}
+	if len(include) > 0 {
+		args = append(args, "--include="+strings.Join(include, ","))
+	}
+	_, err := gitIn(path, args...)
+	return err
+}
+
+type lfsPulledMsg struct {
+	path string
+}
+
+func lfsPullCmd(path string, include []string) tea.Cmd {
+	return func() tea.Msg {
+		if err := lfsPull(path, include); err != nil {
+			return err
+		}
+		return lfsPulledMsg{path: path}
+	}
+}
+
+// runLFSCommand shows the pointer files of the current worktree, or pulls
+// the files matching the given patterns (all of them when there are none).
+func runLFSCommand(cfg Config, args []string) error {
+	path, err := getRepoRoot(".")
+	if err != nil {
+		return err
+	}
+	if !usesLFS(path) {
+		return fmt.Errorf("'%s' does not use Git LFS", path)
+	}
+	if !lfsInstalled() {
+		return fmt.Errorf("git-lfs is not installed")
+	}
+
+	if len(args) == 0 {
+		output, err := gitIn(path, "lfs", "ls-files")
+		if err != nil {
+			return err
+		}
+		total, pointers := parseLFSFiles(output)
+		fmt.Printf("LFS files: %d, pointers: %d\n", total, pointers)
+		return nil
+	}
+	if args[0] != "pull" {
+		return fmt.Errorf("usage: wtree lfs [pull [pattern...]]")
+	}
+
+	if err := lfsPull(path, args[1:]); err != nil {
+		return err
+	}
+	fmt.Printf("Pulled LFS files in '%s'\n", path)
+	return nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseLFSFiles(t *testing.T) {
+	output := "4d7a214614 * assets/logo.png\n" +
+		"b5bb9d8014 - assets/intro.mp4\n" +
+		"9f86d08188 - models/weights bin\n"
+	total, pointers := parseLFSFiles(output)
+	if total != 3 || pointers != 2 {
+		t.Errorf("Expected 3 files and 2 pointers, got %d and %d", total, pointers)
+	}
+	if total, pointers := parseLFSFiles(""); total != 0 || pointers != 0 {
+		t.Errorf("Expected nothing for empty output, got %d and %d", total, pointers)
+	}
+}
+
+// newFakeLFSRepo is newTestRepo with an asset tracked by a stand-in for
+// the LFS filter, so skipping smudge can be tested without git-lfs. The
+// filter writes "smudged" unless GIT_LFS_SKIP_SMUDGE is set.
+func newFakeLFSRepo(t *testing.T) string {
+	t.Helper()
+	repo := newTestRepo(t)
+	runGit(t, repo, "config", "filter.lfs.clean", "cat")
+	runGit(t, repo, "config", "filter.lfs.smudge", `sh -c 'if [ -n "$GIT_LFS_SKIP_SMUDGE" ]; then cat; else echo smudged; fi'`)
+	writeFile(t, filepath.Join(repo, ".gitattributes"), "*.bin filter=lfs diff=lfs merge=lfs -text\n")
+	writeFile(t, filepath.Join(repo, "asset.bin"), "pointer\n")
+	runGit(t, repo, "add", ".gitattributes", "asset.bin")
+	runGit(t, repo, "commit", "-m", "asset")
+	runGit(t, repo, "push", "origin", "main")
+	return repo
+}
+
+func TestSkipSmudge(t *testing.T) {
+	repo := newFakeLFSRepo(t)
+	t.Chdir(repo)
+	t.Setenv("GIT_LFS_SKIP_SMUDGE", "")
+
+	if !usesLFS(repo) {
+		t.Error("Expected the repository to be detected as using LFS")
+	}
+
+	smudged, err := createNewBranchWorktree(".", "feature/smudged", createOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content := readFile(t, filepath.Join(smudged, "asset.bin")); content != "smudged\n" {
+		t.Errorf("Expected the filter to run, got %q", content)
+	}
+
+	sparse := SparseProfile{Name: "docs", Dirs: []string{"docs"}}
+	for i, opts := range []createOptions{{skipSmudge: true}, {skipSmudge: true, sparse: sparse}} {
+		path, err := createNewBranchWorktree(".", fmt.Sprintf("feature/pointers-%d", i), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if content := readFile(t, filepath.Join(path, "asset.bin")); content != "pointer\n" {
+			t.Errorf("Expected a pointer file with %+v, got %q", opts, content)
+		}
+	}
+}
+
+func TestSetupWorktreePullsIncludedLFSFiles(t *testing.T) {
+	if exec.Command("git", "lfs", "version").Run() != nil {
+		t.Skip("git-lfs is not installed")
+	}
+	repo := newTestRepo(t)
+	t.Chdir(repo)
+	runGit(t, repo, "lfs", "install", "--local")
+	runGit(t, repo, "lfs", "track", "*.bin")
+	writeFile(t, filepath.Join(repo, "wanted.bin"), "wanted\n")
+	writeFile(t, filepath.Join(repo, "other.bin"), "other\n")
+	runGit(t, repo, "add", ".gitattributes", "wanted.bin", "other.bin")
+	runGit(t, repo, "commit", "-m", "assets")
+	runGit(t, repo, "push", "origin", "main")
+
+	cfg := Config{LFS: map[string]LFSConfig{"*": {SkipSmudge: true, Include: []string{"wanted.bin"}}}}
+	opts, err := newCreateOptions(".", cfg, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := createNewBranchWorktree(".", "feature/assets", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pointers := countLFSPointers(path); pointers != 2 {
+		t.Errorf("Expected both files to be pointers after creation, got %d", pointers)
+	}
+
+	if _, err := setupWorktree(cfg, path); err != nil {
+		t.Fatal(err)
+	}
+	if content := readFile(t, filepath.Join(path, "wanted.bin")); content != "wanted\n" {
+		t.Errorf("Expected the included file to be pulled, got %q", content)
+	}
+	if pointers := countLFSPointers(path); pointers != 1 {
+		t.Errorf("Expected one pointer left, got %d", pointers)
+	}
+}
+
+func TestLFSPointersAreCached(t *testing.T) {
+	m := initialModel()
+
+	newModel, cmd := m.Update(worktreesMsg{{Path: "/src/repo"}})
+	m = newModel.(model)
+	if cmd == nil || len(m.lfsPointers.entries) != 1 {
+		t.Fatalf("Expected the pointer files to be counted, got %v", m.lfsPointers.entries)
+	}
+	if hasTitle(m.availableActions(), "Pull LFS files") {
+		t.Error("Expected no pull action before the count arrives")
+	}
+
+	newModel, _ = m.Update(lfsPointersMsg{path: "/src/repo", pointers: 3})
+	m = newModel.(model)
+	if item := m.renderWorktreeItem(m.worktrees[0], false); !strings.Contains(item, "LFS: 3 pointer files") {
+		t.Errorf("Expected the pointer count in the details, got %q", item)
+	}
+	if !hasTitle(m.availableActions(), "Pull LFS files") {
+		t.Error("Expected the palette to offer pulling the pointer files")
+	}
+
+	newModel, _ = m.Update(lfsPulledMsg{path: "/src/repo"})
+	m = newModel.(model)
+	if _, ok := m.lfsPointers.entries["/src/repo"]; ok {
+		t.Error("Expected pulling LFS files to drop the count")
+	}
+}
@@ -9,22 +9,29 @@ import (
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/sahilm/fuzzy"
 )
 
 const version = "v0.2.1"
 
 type model struct {
 	worktrees            []Worktree
+	allWorktrees         []Worktree
 	branches             []Branch
 	allBranches          []Branch
+	tags                 []Branch
+	allTags              []Branch
+	worktreeMatches      map[string]worktreeMatch
+	branchMatches        map[string][]int // of the branches or tags shown
 	filterInput          textinput.Model
 	cursor               int
 	selected             map[int]struct{}
-	view                 string // "worktrees" or "branches" or "newbranch"
+	view                 string // one of views
 	filtering            bool
 	viewportHeight       int
 	scrollOffset         int
@@ -39,70 +46,89 @@ type model struct {
 	creatingNewBranch    bool
 	creatingNewBranchName string
 	statusMessage        string
+	keys                 keyMap
+	help                 help.Model
+	showHelp             bool
+	lastClickIndex       int
+	lastClickAt          time.Time
+	paletteOpen          bool
+	paletteInput         textinput.Model
+	paletteCursor        int
+	paletteActions       []paletteAction
+	config               Config
+	repo                 string // repository git commands run in
+	gitOp                string
+	gitPhase             string
+	gitPercent           int
+	repoSignature        string
+	focusWorktree        string
+	prInput              textinput.Model
+	enteringPR           bool
+	forge                ForgeProvider
+	prStatuses           map[string]prStatusEntry
+	diskUsage            asyncCache[DiskUsage]
+	dirty                asyncCache[bool]
+	submodules           asyncCache[SubmoduleStatus]
+	lfsPointers          asyncCache[int] // LFS files still checked out as pointers
+	marked               map[string]bool // worktrees a cleanup applies to
+	confirmingClean      bool
+	cleanPaths           []string
+	tracker              IssueTracker
+	newBranchError       string
+	newBranchWarning     string
+	newBranchRules       NamingRules
+	newBranchDirs        worktreeDirs // read once when the input opens
+	newBranchPrefix      int
+	newBranchIssue       string // issue key the proposed name came from
+	promoteSource        string // worktree whose changes the new branch takes over
+	commitInput          textinput.Model
+	enteringCommit       bool
+	editingMeta          bool
+	metaForm             metaForm
+	editingNote          bool
+	notePath             string
+	noteInput            textarea.Model
+	movingChanges        bool
+	moveSource           Worktree
+	moveTarget           int // index into the other worktrees, -1 before cycling
+	moveUntracked        bool
+	moveInput            textinput.Model
+	stashes              []Stash
+	allStashes           []Stash
+	applyingStash        bool
+	stashPop             bool
+	stashSource          Stash
+	confirmingDrop       bool
+	stashStats           asyncCache[string] // diffstats by stash commit
+	stashTarget          int // index into the worktrees, -1 before cycling
+	stashInput           textinput.Model
+	newSparse            string // sparse profile new worktrees use, "" for full checkouts
+	choosingSparse       bool
+	sparseFor            string // worktree whose profile is picked, "" for new worktrees
+	sparseNames          []string
+	sparseChoice         int
 }
 
 type Worktree struct {
-	Path   string
-	Branch string
-	Head   string
+	Path     string
+	Branch   string
+	Head     string
+	Upstream string
+	Author   string
+	Dirty    bool // loaded in the background, see refreshDirty
+	Tag      string // tag at HEAD of a detached worktree
+	Meta     WorktreeMeta
 }
 
 type Branch struct {
 	Name     string
-	Type     string // "local" or "remote"
+	Type     string // "local", "remote" or "tag"
 	LastCommit string
+	Author   string
 }
 
 type clearStatusMsg struct{}
 
-var (
-	titleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("#7C3AED")).
-			PaddingLeft(2)
-	
-	activeTabStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("#FFFFFF")).
-			Background(lipgloss.Color("#7C3AED")).
-			Padding(0, 2)
-	
-	inactiveTabStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#666666")).
-			Padding(0, 2)
-	
-	selectedItemStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#FFFFFF")).
-				Background(lipgloss.Color("#7C3AED")).
-				Bold(true).
-				PaddingLeft(1).
-				PaddingRight(1)
-	
-	normalItemStyle = lipgloss.NewStyle().
-			PaddingLeft(2)
-	
-	helpStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#666666")).
-			PaddingTop(1).
-			PaddingLeft(2)
-	
-	inputStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#10B981")).
-			Bold(true).
-			PaddingLeft(2)
-	
-	errorStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#EF4444")).
-			Bold(true).
-			PaddingLeft(2)
-	
-	branchTypeStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#10B981"))
-	
-	remoteBranchTypeStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#F59E0B"))
-)
-
 func initialModel() model {
 	filterInput := textinput.New()
 	filterInput.Placeholder = "Type to fuzzy filter branches..."
@@ -114,8 +140,38 @@ func initialModel() model {
 	newBranchInput.CharLimit = 100
 	newBranchInput.Width = 40
 	
+	prInput := textinput.New()
+	prInput.Placeholder = "Pull request number..."
+	prInput.CharLimit = 10
+	prInput.Width = 20
+	
+	commitInput := textinput.New()
+	commitInput.Placeholder = "Commit, tag or revision..."
+	commitInput.CharLimit = 100
+	commitInput.Width = 40
+	
+	moveInput := textinput.New()
+	moveInput.Placeholder = "Worktree or new branch name..."
+	moveInput.CharLimit = 100
+	moveInput.Width = 40
+	
+	stashInput := textinput.New()
+	stashInput.Placeholder = "Worktree name..."
+	stashInput.CharLimit = 100
+	stashInput.Width = 40
+	
+	paletteInput := textinput.New()
+	paletteInput.Placeholder = "Type a command..."
+	paletteInput.CharLimit = 100
+	paletteInput.Width = 40
+	
 	return model{
+		repo:                  ".",
 		selected:              make(map[int]struct{}),
+		prStatuses:            make(map[string]prStatusEntry),
+		submodules:            asyncCache[SubmoduleStatus]{maxAge: submoduleStatusMaxAge},
+		lfsPointers:           asyncCache[int]{maxAge: lfsPointersMaxAge},
+		marked:                make(map[string]bool),
 		view:                  "worktrees",
 		filtering:             false,
 		viewportHeight:        20,
@@ -132,15 +188,25 @@ func initialModel() model {
 		statusMessage:         "",
 		filterInput:           filterInput,
 		newBranchInput:        newBranchInput,
+		paletteInput:          paletteInput,
+		prInput:               prInput,
+		commitInput:           commitInput,
+		moveInput:             moveInput,
+		stashInput:            stashInput,
+		keys:                  defaultKeyMap(),
+		help:                  help.New(),
 	}
 }
 
 func (m model) Init() tea.Cmd {
-	return tea.Batch(
+	cmds := []tea.Cmd{
 		tea.ClearScreen,
-		getWorktreesCmd(),
-		getBranchesCmd(),
-	)
+		getWorktreesCmd(m.repo),
+		getBranchesCmd(m.repo),
+		getTagsCmd(m.repo),
+		getStashesCmd(m.repo),
+	}
+	return tea.Batch(append(cmds, m.startRefreshTimers()...)...)
 }
 
 func clearStatusAfterDelay() tea.Cmd {
@@ -149,10 +215,31 @@ func clearStatusAfterDelay() tea.Cmd {
 	})
 }
 
+// Update handles msg and then starts loading the diffstat of the selected
+// stash, which changes with the cursor, the filter and the list itself.
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	updated, cmd := m.update(msg)
+	next, ok := updated.(model)
+	if !ok {
+		return updated, cmd
+	}
+	if statCmd := next.loadSelectedStashStat(); statCmd != nil {
+		return next, tea.Batch(cmd, statCmd)
+	}
+	return next, cmd
+}
+
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
 	
+	if msg, ok := msg.(repoMsg); ok {
+		if msg.repo != m.repo {
+			return m, nil
+		}
+		return m.update(msg.msg)
+	}
+	
 	// Update text inputs if they're active
 	if m.filtering {
 		m.filterInput, cmd = m.filterInput.Update(msg)
@@ -160,58 +247,199 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			cmds = append(cmds, cmd)
 		}
 		// Apply filter as user types
-		m.filterBranches()
+		m.applyFilter()
 	}
 	
 	if m.creatingBranch {
+		previous := m.newBranchInput.Value()
 		m.newBranchInput, cmd = m.newBranchInput.Update(msg)
 		if cmd != nil {
 			cmds = append(cmds, cmd)
 		}
+		if m.newBranchInput.Value() != previous {
+			m.updateNewBranchError()
+		}
+	}
+	
+	if m.enteringPR {
+		m.prInput, cmd = m.prInput.Update(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	}
+	
+	if m.enteringCommit {
+		m.commitInput, cmd = m.commitInput.Update(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	}
+	
+	if m.movingChanges {
+		m.moveInput, cmd = m.moveInput.Update(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	}
+	
+	if m.applyingStash {
+		m.stashInput, cmd = m.stashInput.Update(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	}
+	
+	if m.editingNote {
+		m.noteInput, cmd = m.noteInput.Update(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	}
+	
+	if m.editingMeta {
+		focus := m.metaForm.focus
+		m.metaForm.inputs[focus], cmd = m.metaForm.inputs[focus].Update(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	}
+	
+	if m.paletteOpen {
+		m.paletteInput, cmd = m.paletteInput.Update(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
 	}
 	
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		keyStr := msg.String()
+		if key.Matches(msg, m.keys.ForceQuit) {
+			return m, tea.Quit
+		}
+		
+		// The help overlay swallows every key except the ones closing it
+		if m.showHelp {
+			if key.Matches(msg, m.keys.Help, m.keys.Cancel) {
+				m.showHelp = false
+			}
+			return m, nil
+		}
+		
+		if m.paletteOpen {
+			return m.updatePalette(msg, cmds)
+		}
+		
+		if m.confirmingClean {
+			switch {
+			case key.Matches(msg, m.keys.Cancel):
+				m.cancelClean()
+			case key.Matches(msg, m.keys.Confirm):
+				return m.confirmClean()
+			}
+			return m, nil
+		}
+		
+		if m.confirmingDrop {
+			switch {
+			case key.Matches(msg, m.keys.Cancel):
+				m.cancelDropStash()
+			case key.Matches(msg, m.keys.Confirm):
+				return m.confirmDropStash()
+			}
+			return m, nil
+		}
+		
+		if m.choosingSparse {
+			switch {
+			case key.Matches(msg, m.keys.Cancel):
+				m.cancelSparsePicker()
+			case key.Matches(msg, m.keys.Confirm):
+				return m.confirmSparsePicker()
+			case key.Matches(msg, m.keys.InputUp, m.keys.Up):
+				m.cycleSparseChoice(-1)
+			case key.Matches(msg, m.keys.InputDown, m.keys.Down):
+				m.cycleSparseChoice(1)
+			}
+			return m, nil
+		}
+		
+		// The note editor takes enter and arrows for itself
+		if m.editingNote {
+			switch {
+			case key.Matches(msg, m.keys.Cancel):
+				m.closeNoteEditor()
+			case key.Matches(msg, m.keys.Save):
+				return m.saveNote()
+			}
+			return m, tea.Batch(cmds...)
+		}
 		
 		// If we're filtering or creating a branch, let the text input handle most keys
-		if m.filtering || m.creatingBranch {
-			switch keyStr {
-			case "ctrl+c":
-				return m, tea.Quit
-			case "esc":
-				if m.filtering {
-					m.filtering = false
-					m.filterInput.SetValue("")
-					m.filterInput.Blur()
-					m.branches = m.allBranches
+		if m.filtering || m.creatingBranch || m.enteringPR || m.enteringCommit || m.editingMeta || m.movingChanges || m.applyingStash {
+			switch {
+			case key.Matches(msg, m.keys.Cancel):
+				if m.applyingStash {
+					m.cancelApplyStash()
+				} else if m.movingChanges {
+					m.cancelMove()
+				} else if m.editingMeta {
+					m.closeMetaForm()
+				} else if m.enteringPR {
+					m.cancelPRInput()
+				} else if m.enteringCommit {
+					m.cancelCommitInput()
+				} else if m.filtering {
+					m.clearFilter()
 					m.cursor = 0
+					m.scrollOffset = 0
 				} else if m.creatingBranch {
 					m.creatingBranch = false
+					m.promoteSource = ""
 					m.newBranchInput.SetValue("")
 					m.newBranchInput.Blur()
 					m.view = "branches"
 				}
-			case "enter":
-				if m.creatingBranch && m.newBranchInput.Value() != "" {
-					return m, createNewBranchWorktreeCmd(m.newBranchInput.Value())
-				} else if m.filtering && len(m.branches) > 0 {
-					// Exit filtering mode and create worktree
-					m.filtering = false
-					m.filterInput.SetValue("")
-					m.filterInput.Blur()
-					m.creatingWorktree = true
-					m.creatingForBranch = m.branches[m.cursor].Name
-					m.statusMessage = fmt.Sprintf("Creating worktree for branch '%s'...", m.branches[m.cursor].Name)
-					return m, createWorktreeCmd(m.branches[m.cursor])
+			case key.Matches(msg, m.keys.Confirm):
+				if m.applyingStash {
+					return m.confirmApplyStash()
+				} else if m.movingChanges {
+					return m.confirmMove()
+				} else if m.editingMeta {
+					return m.confirmMetaForm()
+				} else if m.enteringPR {
+					return m.confirmPRInput()
+				} else if m.enteringCommit {
+					return m.confirmCommitInput()
+				} else if m.creatingBranch {
+					return m.confirmNewBranch()
+				} else if m.filtering {
+					return m.activateSelection()
 				}
-			case "up", "k":
-				if m.filtering && m.cursor > 0 {
+			case key.Matches(msg, m.keys.InputUp):
+				if m.applyingStash {
+					m.cycleStashTarget(-1)
+				} else if m.movingChanges {
+					m.cycleMoveTarget(-1)
+				} else if m.editingMeta {
+					cmds = append(cmds, m.metaForm.move(-1))
+				} else if m.creatingBranch {
+					m.cyclePrefix(-1)
+				} else if m.filtering && m.cursor > 0 {
 					m.cursor--
 					m.adjustScrollOffset()
 				}
-			case "down", "j":
-				if m.filtering && m.cursor < len(m.branches)-1 {
+			case key.Matches(msg, m.keys.Untracked) && m.movingChanges:
+				m.moveUntracked = !m.moveUntracked
+			case key.Matches(msg, m.keys.InputDown):
+				if m.applyingStash {
+					m.cycleStashTarget(1)
+				} else if m.movingChanges {
+					m.cycleMoveTarget(1)
+				} else if m.editingMeta {
+					cmds = append(cmds, m.metaForm.move(1))
+				} else if m.creatingBranch {
+					m.cyclePrefix(1)
+				} else if m.filtering && m.cursor < m.listLen()-1 {
 					m.cursor++
 					m.adjustScrollOffset()
 				}
@@ -220,79 +448,196 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Batch(cmds...)
 		}
 		
-		// Handle key combinations and special keys when not in input mode
+		// Handle key bindings when not in input mode
 		switch {
-		case keyStr == "ctrl+c" || keyStr == "q":
+		case key.Matches(msg, m.keys.Quit):
 			return m, tea.Quit
 			
-		case keyStr == "enter":
-			if m.view == "worktrees" && len(m.worktrees) > 0 {
-				return m, openWorktreeCmd(m.worktrees[m.cursor])
-			} else if m.view == "branches" && len(m.branches) > 0 {
-				// Set creating status
-				m.creatingWorktree = true
-				m.creatingForBranch = m.branches[m.cursor].Name
-				m.statusMessage = fmt.Sprintf("Creating worktree for branch '%s'...", m.branches[m.cursor].Name)
-				return m, createWorktreeCmd(m.branches[m.cursor])
-			}
+		case key.Matches(msg, m.keys.Help):
+			m.showHelp = true
 			
-		case keyStr == "up" || keyStr == "k":
+		case key.Matches(msg, m.keys.Select):
+			return m.activateSelection()
+			
+		case key.Matches(msg, m.keys.Up):
 			if m.cursor > 0 {
 				m.cursor--
 				m.adjustScrollOffset()
 			}
 			
-		case keyStr == "down" || keyStr == "j":
-			if m.view == "worktrees" && m.cursor < len(m.worktrees)-1 {
-				m.cursor++
-				m.adjustScrollOffset()
-			} else if m.view == "branches" && m.cursor < len(m.branches)-1 {
+		case key.Matches(msg, m.keys.Down):
+			if m.cursor < m.listLen()-1 {
 				m.cursor++
 				m.adjustScrollOffset()
 			}
 			
-		case keyStr == "tab":
-			if !m.filtering && !m.creatingBranch {
-				if m.view == "worktrees" {
-					m.view = "branches"
-				} else {
-					m.view = "worktrees"
-				}
-				m.cursor = 0
-				m.scrollOffset = 0
+		case key.Matches(msg, m.keys.Top):
+			m.cursor = 0
+			m.adjustScrollOffset()
+			
+		case key.Matches(msg, m.keys.Bottom):
+			if m.listLen() > 0 {
+				m.cursor = m.listLen() - 1
+				m.adjustScrollOffset()
 			}
 			
-		case (keyStr == "/" || keyStr == "f") && m.view == "branches" && !m.filtering && !m.creatingBranch:
-			m.filtering = true
-			m.filterInput.SetValue("")
-			m.filterInput.Focus()
-			cmd = m.filterInput.Focus()
-			cmds = append(cmds, cmd)
+		case key.Matches(msg, m.keys.SwitchView):
+			m.switchView(nextView(m.view))
 			
-		case keyStr == "n" && m.view == "branches" && !m.filtering && !m.creatingBranch:
-			m.creatingBranch = true
-			m.newBranchInput.SetValue("")
-			m.newBranchInput.Focus()
-			cmd = m.newBranchInput.Focus()
-			cmds = append(cmds, cmd)
+		case key.Matches(msg, m.keys.Filter):
+			cmds = append(cmds, m.startFilter())
+			
+		case key.Matches(msg, m.keys.NewBranch) && m.view == "branches":
+			cmds = append(cmds, m.startNewBranch())
+			
+		case key.Matches(msg, m.keys.Palette):
+			cmds = append(cmds, m.openPalette())
+			
+		case key.Matches(msg, m.keys.PullRequest):
+			cmds = append(cmds, m.startPRInput())
+			
+		case key.Matches(msg, m.keys.CheckoutCommit):
+			cmds = append(cmds, m.startCommitInput())
+			
+		case key.Matches(msg, m.keys.Scratch):
+			return m.startScratch()
+			
+		case key.Matches(msg, m.keys.Fetch):
+			return m.startFetch()
+			
+		case key.Matches(msg, m.keys.Pull) && m.view == "worktrees" && len(m.worktrees) > 0:
+			return m.startPull(m.worktrees[m.cursor])
+			
+		case key.Matches(msg, m.keys.Push) && m.view == "worktrees" && len(m.worktrees) > 0:
+			return m.startPush(m.worktrees[m.cursor])
+			
+		case key.Matches(msg, m.keys.Edit) && m.view == "worktrees" && len(m.worktrees) > 0:
+			cmds = append(cmds, m.startMetaForm(m.worktrees[m.cursor]))
+			
+		case key.Matches(msg, m.keys.Note) && m.view == "worktrees" && len(m.worktrees) > 0:
+			cmds = append(cmds, m.startNoteEditor(m.worktrees[m.cursor]))
+			
+		case key.Matches(msg, m.keys.Promote) && m.view == "worktrees" && len(m.worktrees) > 0:
+			cmds = append(cmds, m.startPromote(m.worktrees[m.cursor]))
+			
+		case key.Matches(msg, m.keys.MoveChanges) && m.view == "worktrees" && len(m.worktrees) > 0:
+			cmds = append(cmds, m.startMove(m.worktrees[m.cursor]))
+			
+		case key.Matches(msg, m.keys.Sparse) && m.view == "worktrees" && len(m.worktrees) > 0:
+			cmds = append(cmds, m.startSparsePicker(m.worktrees[m.cursor].Path))
 			
-		case keyStr == "d" && !m.filtering && !m.creatingBranch && !m.deletingWorktree && m.view == "worktrees" && len(m.worktrees) > 0:
+		case key.Matches(msg, m.keys.Sparse) && (m.view == "branches" || m.view == "tags"):
+			cmds = append(cmds, m.startSparsePicker(""))
+			
+		case key.Matches(msg, m.keys.Mark) && m.view == "worktrees" && len(m.worktrees) > 0:
+			m.toggleMark(m.worktrees[m.cursor].Path)
+			
+		case key.Matches(msg, m.keys.Clean) && m.view == "worktrees" && len(m.worktrees) > 0:
+			cmds = append(cmds, m.startClean())
+			
+		case key.Matches(msg, m.keys.Pin) && m.view == "worktrees" && len(m.worktrees) > 0:
+			return m, togglePinCmd(m.repo, m.worktrees[m.cursor])
+			
+		case key.Matches(msg, m.keys.Delete) && !m.deletingWorktree && m.view == "worktrees" && len(m.worktrees) > 0:
 			return m, deleteWorktreeCmd(m.worktrees[m.cursor])
 			
+		case key.Matches(msg, m.keys.Apply) && m.view == "stashes" && len(m.stashes) > 0:
+			cmds = append(cmds, m.startApplyStash(m.stashes[m.cursor], false))
+			
+		case key.Matches(msg, m.keys.Pop) && m.view == "stashes" && len(m.stashes) > 0:
+			cmds = append(cmds, m.startApplyStash(m.stashes[m.cursor], true))
+			
+		case key.Matches(msg, m.keys.Delete) && m.view == "stashes" && len(m.stashes) > 0:
+			m.startDropStash(m.stashes[m.cursor])
+			
 		}
 
 	case worktreesMsg:
-		m.worktrees = []Worktree(msg)
+		m.allWorktrees = sortWorktrees([]Worktree(msg), m.config.Sort)
+		dirtyCmd := m.refreshDirty()
+		m.filterWorktrees()
+		cmd = tea.Batch(dirtyCmd, m.refreshPRStatuses(), m.refreshDiskUsage(), m.refreshSubmoduleStatus(), m.refreshLFSPointers())
+		return m, cmd
+	case worktreeDirtyMsg:
+		m.dirty.set(msg.path, msg.dirty)
+		for i := range m.allWorktrees {
+			if m.allWorktrees[i].Path == msg.path {
+				m.allWorktrees[i].Dirty = msg.dirty
+			}
+		}
+		m.filterWorktrees()
+	case diskUsageMsg:
+		m.diskUsage.set(msg.path, msg.usage)
+	case submoduleStatusMsg:
+		m.submodules.set(msg.path, msg.status)
+	case lfsPointersMsg:
+		m.lfsPointers.set(msg.path, msg.pointers)
+	case ignoredCleanedMsg:
+		for _, path := range msg.paths {
+			m.diskUsage.forget(path)
+			delete(m.marked, path)
+		}
+		m.statusMessage = fmt.Sprintf("%s Removed ignored files in %d worktree(s), freeing %s", markers.OK, len(msg.paths), formatSize(msg.freed))
+		return m, tea.Batch(
+			getWorktreesCmd(m.repo),
+			clearStatusAfterDelay(),
+		)
+	case issueBranchProposedMsg:
+		if m.creatingBranch {
+			m.newBranchInput.SetValue(m.withPrefix(msg.branch))
+			m.newBranchInput.CursorEnd()
+			m.updateNewBranchError()
+			m.newBranchIssue = msg.key
+			m.statusMessage = fmt.Sprintf("%s: %s", msg.key, msg.title)
+			return m, clearStatusAfterDelay()
+		}
+	case warningMsg:
+		updated, cmd := m.Update(msg.msg)
+		m = updated.(model)
+		m.statusMessage = strings.TrimSpace(fmt.Sprintf("%s %s %s", m.statusMessage, markers.Error, msg.warning))
+		return m, tea.Batch(cmd, clearStatusAfterDelay())
+	case prStatusMsg:
+		m.prStatuses[msg.branch] = prStatusEntry{status: msg.status, fetchedAt: time.Now()}
 	case branchesMsg:
 		m.allBranches = []Branch(msg)
-		m.branches = m.allBranches
 		m.filterBranches()
+	case tagsMsg:
+		m.allTags = []Branch(msg)
+		m.filterTags()
+	case stashesMsg:
+		m.allStashes = []Stash(msg)
+		m.filterStashes()
+	case stashAppliedMsg:
+		verb := "Applied"
+		if msg.pop {
+			verb = "Popped"
+		}
+		m.statusMessage = fmt.Sprintf("%s %s %s to %s", markers.OK, verb, msg.ref, filepath.Base(msg.path))
+		return m, tea.Batch(
+			getWorktreesCmd(m.repo),
+			getStashesCmd(m.repo),
+			clearStatusAfterDelay(),
+		)
+	case stashStatMsg:
+		m.stashStats.set(msg.commit, msg.stat)
+	case stashDroppedMsg:
+		// The commit can still be recovered with git stash apply <sha>
+		m.statusMessage = fmt.Sprintf("%s Dropped %s (%s)", markers.OK, msg.ref, msg.commit[:7])
+		return m, tea.Batch(
+			getStashesCmd(m.repo),
+			clearStatusAfterDelay(),
+		)
 	case newBranchCreatingMsg:
+		opts, err := m.worktreeOptions(m.newSparse)
+		if err != nil {
+			m.statusMessage = fmt.Sprintf("%s %v", markers.Error, err)
+			return m, clearStatusAfterDelay()
+		}
 		// Show immediate feedback while creating
 		m.creatingNewBranch = true
 		m.creatingNewBranchName = msg.branchName
 		m.statusMessage = fmt.Sprintf("Creating new branch '%s' and worktree...", msg.branchName)
-		return m, performCreateNewBranchWorktreeCmd(msg.branchName)
+		return m, performCreateNewBranchWorktreeCmd(m.repo, msg.branchName, opts)
 	case newBranchCreatedMsg:
 		m.creatingBranch = false
 		m.creatingNewBranch = false
@@ -302,9 +647,18 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.view = "worktrees"
 		m.cursor = 0
 		m.scrollOffset = 0
-		m.statusMessage = "✅ New branch and worktree created successfully"
+		// Land on the new worktree once the refreshed list arrives
+		m.focusWorktree = msg.path
+		m.statusMessage = markers.OK + " New branch and worktree created successfully"
+		if m.promoteSource != "" {
+			m.statusMessage = fmt.Sprintf("%s Moved changes of %s to a new branch", markers.OK, filepath.Base(m.promoteSource))
+			m.promoteSource = ""
+		}
+		issue := m.newBranchIssue
+		m.newBranchIssue = ""
 		return m, tea.Batch(
-			getWorktreesCmd(),
+			linkIssueCmd(m.repo, msg.path, issue),
+			m.setupWorktreeCmd(msg.path),
 			clearStatusAfterDelay(),
 		)
 	case deletingWorktreeMsg:
@@ -313,26 +667,172 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Find the worktree to delete
 		for _, worktree := range m.worktrees {
 			if worktree.Path == msg.path {
-				return m, performDeleteWorktreeCmd(worktree)
+				return m, performDeleteWorktreeCmd(m.repo, worktree)
 			}
 		}
 		return m, nil
 	case worktreeDeletedMsg:
 		m.deletingWorktree = false
 		m.deletingPath = ""
-		return m, getWorktreesCmd()
+		return m, getWorktreesCmd(m.repo)
+	case gitProgressMsg:
+		m.gitOp = msg.op
+		m.gitPhase = msg.phase
+		m.gitPercent = msg.percent
+		return m, waitForGitOp(msg.ch)
+	case gitOpDoneMsg:
+		m.gitOp = ""
+		m.gitPhase = ""
+		m.gitPercent = 0
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("%s %s failed: %v", markers.Error, msg.op, msg.err)
+			return m, clearStatusAfterDelay()
+		}
+		m.statusMessage = fmt.Sprintf("%s %s complete", markers.OK, msg.op)
+		return m, tea.Batch(
+			getWorktreesCmd(m.repo),
+			getBranchesCmd(m.repo),
+			getTagsCmd(m.repo),
+			clearStatusAfterDelay(),
+		)
+	case autoRefreshMsg, backgroundFetchedMsg, repoChangedMsg, repoUnchangedMsg:
+		return m.handleRefreshMsg(msg)
+	case worktreesPrunedMsg:
+		m.statusMessage = markers.OK + " Pruned stale worktree metadata"
+		return m, tea.Batch(
+			getWorktreesCmd(m.repo),
+			clearStatusAfterDelay(),
+		)
+	case hookFinishedMsg:
+		m.statusMessage = fmt.Sprintf("%s Hook '%s' finished in %s", markers.OK, msg.name, filepath.Base(msg.path))
+		return m, clearStatusAfterDelay()
+	case repoSwitchedMsg:
+		previous := m.repo
+		m.repo = msg.path
+		m.allWorktrees = nil
+		m.allBranches = nil
+		m.allTags = nil
+		m.allStashes = nil
+		m.newSparse = defaultSparse(m.repo, m.config)
+		m.repoSignature = ""
+		m.prStatuses = make(map[string]prStatusEntry)
+		m.diskUsage.clear()
+		m.dirty.clear()
+		m.submodules.clear()
+		m.lfsPointers.clear()
+		m.stashStats.clear()
+		m.marked = make(map[string]bool)
+		if m.forge != nil {
+			// The forge repository is detected from the new repo's remote
+			m.forge = loadForgeProvider(m.repo, m.config.Forge)
+		}
+		m.switchView("worktrees")
+		m.statusMessage = fmt.Sprintf("%s Switched to %s", markers.OK, msg.path)
+		cmds = append(cmds,
+			getWorktreesCmd(m.repo),
+			getBranchesCmd(m.repo),
+			getTagsCmd(m.repo),
+			getStashesCmd(m.repo),
+			clearStatusAfterDelay(),
+		)
+		if m.config.Refresh.watchEnabled() && m.repo != previous {
+			// The watcher of the previous repository stops as its
+			// messages are dropped
+			cmds = append(cmds, watchRepoCmd(m.repo, ""))
+		}
+		return m, tea.Batch(cmds...)
+	case scratchCreatedMsg:
+		m.view = "worktrees"
+		m.focusWorktree = msg.path
+		m.creatingWorktree = false
+		m.creatingForBranch = ""
+		m.statusMessage = fmt.Sprintf("%s Created scratch worktree %s", markers.OK, filepath.Base(msg.path))
+		return m, tea.Batch(
+			getWorktreesCmd(m.repo),
+			m.setupWorktreeCmd(msg.path),
+			clearStatusAfterDelay(),
+		)
+	case changesMovedMsg:
+		m.focusWorktree = msg.path
+		m.statusMessage = fmt.Sprintf("%s Moved changes to %s", markers.OK, filepath.Base(msg.path))
+		cmds = append(cmds,
+			getWorktreesCmd(m.repo),
+			getBranchesCmd(m.repo),
+			clearStatusAfterDelay(),
+		)
+		// A worktree created for a new branch is set up like any other,
+		// which pulls the LFS files that skip_smudge left out
+		if msg.created {
+			cmds = append(cmds, m.setupWorktreeCmd(msg.path))
+		}
+		return m, tea.Batch(cmds...)
+	case worktreeSetUpMsg:
+		m.statusMessage = fmt.Sprintf("%s Set up %s: %s", markers.OK, filepath.Base(msg.path), strings.Join(msg.steps, ", "))
+		m.submodules.forget(msg.path)
+		m.lfsPointers.forget(msg.path)
+		return m, tea.Batch(
+			getWorktreesCmd(m.repo),
+			clearStatusAfterDelay(),
+		)
+	case lfsPulledMsg:
+		m.statusMessage = fmt.Sprintf("%s Pulled LFS files in %s", markers.OK, filepath.Base(msg.path))
+		m.lfsPointers.forget(msg.path)
+		return m, tea.Batch(
+			getWorktreesCmd(m.repo),
+			clearStatusAfterDelay(),
+		)
+	case sparseProfileSetMsg:
+		m.statusMessage = fmt.Sprintf("%s %s now uses the %s checkout", markers.OK, filepath.Base(msg.path), describeSparse(msg.profile))
+		return m, tea.Batch(
+			getWorktreesCmd(m.repo),
+			clearStatusAfterDelay(),
+		)
+	case worktreePinnedMsg:
+		verb := "Unpinned"
+		if msg.pinned {
+			verb = "Pinned"
+		}
+		m.statusMessage = fmt.Sprintf("%s %s %s", markers.OK, verb, filepath.Base(msg.path))
+		return m, tea.Batch(
+			getWorktreesCmd(m.repo),
+			clearStatusAfterDelay(),
+		)
+	case metadataSavedMsg:
+		m.statusMessage = fmt.Sprintf("%s Saved details of %s", markers.OK, filepath.Base(msg.path))
+		return m, tea.Batch(
+			getWorktreesCmd(m.repo),
+			clearStatusAfterDelay(),
+		)
+	case gcFinishedMsg:
+		m.statusMessage = fmt.Sprintf("%s Removed %d expired worktrees", markers.OK, len(msg.result.removed))
+		if kept := len(msg.result.skipped); kept > 0 {
+			m.statusMessage += fmt.Sprintf(", kept %d with unsaved work", kept)
+		}
+		return m, tea.Batch(
+			getWorktreesCmd(m.repo),
+			getBranchesCmd(m.repo),
+			clearStatusAfterDelay(),
+		)
 	case worktreeCreatedMsg:
 		// Switch to worktrees view and refresh the list
 		m.view = "worktrees"
 		m.cursor = 0
 		m.scrollOffset = 0
+		m.focusWorktree = msg.path
 		m.creatingWorktree = false
 		m.creatingForBranch = ""
-		m.statusMessage = fmt.Sprintf("✅ Successfully created worktree for branch '%s'", msg.branch)
+		if msg.detached {
+			m.statusMessage = fmt.Sprintf("%s Successfully created detached worktree at '%s'", markers.OK, msg.branch)
+		} else {
+			m.statusMessage = fmt.Sprintf("%s Successfully created worktree for branch '%s'", markers.OK, msg.branch)
+		}
 		return m, tea.Batch(
-			getWorktreesCmd(),
+			getWorktreesCmd(m.repo),
+			m.setupWorktreeCmd(msg.path),
 			clearStatusAfterDelay(),
 		)
+	case tea.MouseMsg:
+		return m.handleMouse(msg)
 	case tea.WindowSizeMsg:
 		m.windowWidth = msg.Width
 		m.windowHeight = msg.Height
@@ -345,12 +845,19 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.creatingNewBranch {
 				m.creatingNewBranch = false
 				m.creatingNewBranchName = ""
+				if m.promoteSource != "" {
+					// The changes stayed put; b starts over
+					m.promoteSource = ""
+					m.creatingBranch = false
+					m.newBranchInput.SetValue("")
+					m.newBranchInput.Blur()
+				}
 			}
 			if m.creatingWorktree {
 				m.creatingWorktree = false
 				m.creatingForBranch = ""
 			}
-			m.statusMessage = fmt.Sprintf("❌ Error: %v", err)
+			m.statusMessage = fmt.Sprintf("%s Error: %v", markers.Error, err)
 			return m, clearStatusAfterDelay()
 		}
 	}
@@ -361,7 +868,62 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// activateSelection opens the selected worktree, creates a worktree for
+// the selected branch or applies the selected stash, leaving filter mode
+// first if it is active.
+func (m model) activateSelection() (model, tea.Cmd) {
+	if m.view == "worktrees" && len(m.worktrees) > 0 {
+		worktree := m.worktrees[m.cursor]
+		if m.filtering {
+			// Exit filtering mode, keeping the cursor on the opened worktree
+			m.clearFilter()
+			for i, wt := range m.worktrees {
+				if wt.Path == worktree.Path {
+					m.cursor = i
+				}
+			}
+			m.adjustScrollOffset()
+		}
+		return m, openWorktreeCmd(m.repo, worktree)
+	} else if branch, ok := m.selectedBranch(); ok {
+		opts, err := m.worktreeOptions(m.newSparse)
+		if err != nil {
+			m.statusMessage = fmt.Sprintf("%s %v", markers.Error, err)
+			return m, clearStatusAfterDelay()
+		}
+		if m.filtering {
+			m.filtering = false
+			m.filterInput.SetValue("")
+			m.filterInput.Blur()
+		}
+		// Set creating status
+		m.creatingWorktree = true
+		m.creatingForBranch = branch.Name
+		if branch.Type == "tag" {
+			m.statusMessage = fmt.Sprintf("Creating detached worktree at tag '%s'...", branch.Name)
+		} else {
+			m.statusMessage = fmt.Sprintf("Creating worktree for branch '%s'...", branch.Name)
+		}
+		return m, createWorktreeCmd(m.repo, branch, opts)
+	} else if m.view == "stashes" && len(m.stashes) > 0 {
+		stash := m.stashes[m.cursor]
+		if m.filtering {
+			m.clearFilter()
+			m.restoreSelection("stashes", stash.Commit)
+		}
+		return m, m.startApplyStash(stash, false)
+	}
+	return m, nil
+}
+
 func (m model) View() string {
+	if m.showHelp {
+		return m.renderHelpOverlay()
+	}
+	if m.paletteOpen {
+		return m.renderPalette()
+	}
+	
 	var content strings.Builder
 	
 	// Header with tabs
@@ -370,39 +932,48 @@ func (m model) View() string {
 	content.WriteString("\n\n")
 
 	// Show status message if any
-	if m.statusMessage != "" {
-		statusStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#10B981")).
-			Bold(true).
-			PaddingLeft(2)
-		content.WriteString(statusStyle.Render(m.statusMessage))
-		content.WriteString("\n\n")
-	} else if m.creatingWorktree {
-		// Show creating status
-		creatingStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#F59E0B")).
-			Bold(true).
-			PaddingLeft(2)
-		content.WriteString(creatingStyle.Render("⏳ Creating worktree..."))
-		content.WriteString("\n\n")
-	} else if m.creatingNewBranch {
-		// Show creating new branch status
-		creatingStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#F59E0B")).
-			Bold(true).
-			PaddingLeft(2)
-		content.WriteString(creatingStyle.Render(fmt.Sprintf("⏳ Creating new branch '%s'...", m.creatingNewBranchName)))
+	if status := m.renderStatus(); status != "" {
+		content.WriteString(status)
 		content.WriteString("\n\n")
 	}
 
 	if m.view == "worktrees" {
-		if len(m.worktrees) == 0 {
-			content.WriteString(errorStyle.Render("No worktrees found."))
+		if m.movingChanges {
+			content.WriteString(m.renderMoveInput())
+			content.WriteString("\n")
+		} else if m.choosingSparse {
+			content.WriteString(m.renderSparsePicker())
+			content.WriteString("\n")
+		} else if m.confirmingClean {
+			content.WriteString(m.renderCleanConfirm())
+			content.WriteString("\n")
+		} else if m.enteringPR {
+			content.WriteString(inputStyle.Render("Pull request: "))
+			content.WriteString(m.prInput.View())
+			content.WriteString("\n")
+		} else if m.enteringCommit {
+			content.WriteString(inputStyle.Render("Check out commit: "))
+			content.WriteString(m.commitInput.View())
+			content.WriteString("\n")
+		} else if m.filtering {
+			content.WriteString(inputStyle.Render("Filter: "))
+			content.WriteString(m.filterInput.View())
+			content.WriteString("\n")
+		}
+
+		if m.editingNote {
+			content.WriteString(m.renderNoteEditor())
+		} else if m.editingMeta {
+			content.WriteString(m.renderMetaForm())
+		} else if len(m.worktrees) == 0 {
+			if m.filtering {
+				content.WriteString(errorStyle.Render("No worktrees match filter."))
+			} else {
+				content.WriteString(errorStyle.Render("No worktrees found."))
+			}
 			content.WriteString("\n")
 		} else {
-			// Calculate how many worktrees can fit (each takes 2 lines)
-			maxWorktreesInView := m.viewportHeight / 2
-			start, end := m.getViewportRangeForWorktrees(len(m.worktrees), maxWorktreesInView)
+			start, end := m.getViewportRangeForWorktrees(len(m.worktrees))
 			for i := start; i < end; i++ {
 				if i >= len(m.worktrees) {
 					break
@@ -413,83 +984,185 @@ func (m model) View() string {
 				content.WriteString("\n")
 			}
 			// Add scroll indicator
-			if len(m.worktrees) > maxWorktreesInView {
+			if end-start < len(m.worktrees) {
 				content.WriteString(m.renderScrollIndicator(end-start, len(m.worktrees)))
 				content.WriteString("\n")
 			}
 		}
+	} else if m.view == "stashes" {
+		if m.applyingStash {
+			content.WriteString(m.renderApplyStashInput())
+			content.WriteString("\n")
+		} else if m.confirmingDrop {
+			content.WriteString(m.renderDropConfirm())
+			content.WriteString("\n")
+		} else if m.filtering {
+			content.WriteString(inputStyle.Render("Filter: "))
+			content.WriteString(m.filterInput.View())
+			content.WriteString("\n")
+		}
 		
-		content.WriteString(helpStyle.Render("Press 'enter' to open, 'd' to delete, 'tab' to switch to branches"))
+		if len(m.stashes) == 0 {
+			if m.filtering {
+				content.WriteString(errorStyle.Render("No stashes match filter."))
+			} else {
+				content.WriteString(errorStyle.Render("No stashes found."))
+			}
+			content.WriteString("\n")
+		} else {
+			start, end := m.getViewportRange(len(m.stashes))
+			for i := start; i < end; i++ {
+				content.WriteString(m.renderStashItem(m.stashes[i], i == m.cursor))
+				content.WriteString("\n")
+			}
+			if end-start < len(m.stashes) {
+				content.WriteString(helpStyle.Render(fmt.Sprintf(" (%d/%d)", end-start, len(m.stashes))))
+				content.WriteString("\n")
+			}
+			content.WriteString("\n")
+			content.WriteString(m.renderStashPreview(m.stashes[m.cursor]))
+		}
 	} else {
 		if m.creatingBranch {
-			content.WriteString(inputStyle.Render("New branch name: "))
+			if len(m.newBranchRules.Prefixes) > 0 {
+				content.WriteString(m.renderPrefixPicker())
+				content.WriteString("\n")
+			}
+			label := "New branch name: "
+			if m.promoteSource != "" {
+				label = fmt.Sprintf("New branch for the changes of %s: ", filepath.Base(m.promoteSource))
+			}
+			content.WriteString(inputStyle.Render(label))
 			content.WriteString(m.newBranchInput.View())
 			content.WriteString("\n")
+			if m.newBranchError != "" {
+				content.WriteString(errorStyle.Render(markers.Error + " " + m.newBranchError))
+				content.WriteString("\n")
+			} else if m.newBranchWarning != "" {
+				content.WriteString(pendingStyle.Render(m.newBranchWarning))
+				content.WriteString("\n")
+			}
+		} else if m.enteringPR {
+			content.WriteString(inputStyle.Render("Pull request: "))
+			content.WriteString(m.prInput.View())
+			content.WriteString("\n")
+		} else if m.enteringCommit {
+			content.WriteString(inputStyle.Render("Check out commit: "))
+			content.WriteString(m.commitInput.View())
+			content.WriteString("\n")
+		} else if m.choosingSparse {
+			content.WriteString(m.renderSparsePicker())
+			content.WriteString("\n")
 		} else if m.filtering {
 			content.WriteString(inputStyle.Render("Filter: "))
 			content.WriteString(m.filterInput.View())
 			content.WriteString("\n")
+		} else if m.newSparse != "" {
+			content.WriteString(pathStyle.Render("New worktrees use the " + describeSparse(m.newSparse) + " checkout"))
+			content.WriteString("\n")
 		} else {
 			content.WriteString("\n")
 		}
 		
+		// Tags share the branch list's layout
+		branches, noun := m.branches, "branches"
+		if m.view == "tags" {
+			branches, noun = m.tags, "tags"
+		}
 		if m.creatingBranch {
 			// Don't show branch list when creating new branch
-		} else if len(m.branches) == 0 {
+		} else if len(branches) == 0 {
 			if m.filtering {
-				content.WriteString(errorStyle.Render("No branches match filter."))
+				content.WriteString(errorStyle.Render("No " + noun + " match filter."))
 			} else {
-				content.WriteString(errorStyle.Render("No branches found."))
+				content.WriteString(errorStyle.Render("No " + noun + " found."))
 			}
 			content.WriteString("\n")
 		} else {
-			start, end := m.getViewportRange(len(m.branches))
+			start, end := m.getViewportRange(len(branches))
 			for i := start; i < end; i++ {
-				if i >= len(m.branches) {
+				if i >= len(branches) {
 					break
 				}
-				branch := m.branches[i]
+				branch := branches[i]
 				itemContent := m.renderBranchItem(branch, i == m.cursor)
 				content.WriteString(itemContent)
 				content.WriteString("\n")
 			}
 			// Add scroll indicator
-			if len(m.branches) > m.viewportHeight {
-				content.WriteString(m.renderScrollIndicator(end-start, len(m.branches)))
+			if len(branches) > m.viewportHeight {
+				content.WriteString(m.renderScrollIndicator(end-start, len(branches)))
 				content.WriteString("\n")
 			}
 		}
-		
-		if m.creatingBranch {
-			content.WriteString(helpStyle.Render("Press 'enter' to create, 'esc' to cancel (standard text editing keys work)"))
-		} else if m.filtering {
-			content.WriteString(helpStyle.Render("Type to fuzzy filter, 'enter' to select, 'esc' to cancel (all text editing keys work)"))
-		} else {
-			content.WriteString(helpStyle.Render("Press 'enter' to create worktree, 'n' for new branch, 'f' or '/' to filter, 'tab' to switch to worktrees"))
-		}
 	}
 
+	// Key hints are generated from the key map for the current mode
+	content.WriteString(helpStyle.Render(m.help.ShortHelpView(m.helpKeys().ShortHelp())))
+	return content.String()
+}
+
+// renderHelpOverlay lists every binding for the current mode.
+func (m model) renderHelpOverlay() string {
+	var content strings.Builder
+	
+	content.WriteString(m.renderHeader())
+	content.WriteString("\n\n")
+	content.WriteString(titleStyle.Render("Key bindings — " + m.modeName()))
+	content.WriteString("\n\n")
+	
+	fullHelp := m.help
+	fullHelp.ShowAll = true
+	content.WriteString(normalItemStyle.Render(fullHelp.View(m.helpKeys())))
 	content.WriteString("\n")
-	content.WriteString(helpStyle.Render("Press 'q' to quit."))
+	content.WriteString(helpStyle.Render(fmt.Sprintf("Press %s to close.", withHelp(m.keys.Help, "").Help().Key)))
 	return content.String()
 }
 
+func (m model) modeName() string {
+	switch {
+	case m.enteringPR:
+		return "pull request"
+	case m.creatingBranch:
+		return "new branch"
+	case m.filtering:
+		return "filter " + m.view
+	default:
+		return m.view
+	}
+}
+
+// renderStatus returns the single status line shown under the header, or
+// an empty string when there is nothing to report.
+func (m model) renderStatus() string {
+	switch {
+	case m.statusMessage != "":
+		return statusStyle.Render(m.statusMessage)
+	case m.creatingWorktree:
+		// Show creating status
+		return pendingStyle.Render(markers.Pending + " Creating worktree...")
+	case m.creatingNewBranch:
+		// Show creating new branch status
+		return pendingStyle.Render(fmt.Sprintf("%s Creating new branch '%s'...", markers.Pending, m.creatingNewBranchName))
+	case m.gitOp != "":
+		// Show progress of a running fetch/pull/push
+		progress := fmt.Sprintf("%s %s: %s", markers.Pending, m.gitOp, m.gitPhase)
+		if m.gitPercent > 0 {
+			progress += fmt.Sprintf(" %s %d%%", renderProgressBar(m.gitPercent, 20), m.gitPercent)
+		}
+		return pendingStyle.Render(progress)
+	}
+	return ""
+}
+
 func (m model) renderHeader() string {
 	var tabs []string
 	
-	if m.view == "worktrees" {
-		tabs = append(tabs, activeTabStyle.Render("Worktrees"))
-		tabs = append(tabs, inactiveTabStyle.Render("Branches"))
-	} else {
-		tabs = append(tabs, inactiveTabStyle.Render("Worktrees"))
-		tabs = append(tabs, activeTabStyle.Render("Branches"))
+	for _, view := range views {
+		tabs = append(tabs, m.renderTab(view))
 	}
 	
 	// Add version to the right
-	versionStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#666666")).
-		PaddingLeft(2)
-	
 	tabsWidth := lipgloss.Width(lipgloss.JoinHorizontal(lipgloss.Top, tabs...))
 	versionText := versionStyle.Render(version)
 	versionWidth := lipgloss.Width(versionText)
@@ -508,24 +1181,82 @@ func (m model) renderHeader() string {
 	)
 }
 
+func (m model) renderTab(view string) string {
+	title := strings.ToUpper(view[:1]) + view[1:]
+	if view == m.view {
+		return activeTabStyle.Render(title)
+	}
+	return inactiveTabStyle.Render(title)
+}
+
 func (m model) renderWorktreeItem(worktree Worktree, selected bool) string {
 	// Create main content line with basename and branch
-	mainContent := fmt.Sprintf("%s (%s)", filepath.Base(worktree.Path), worktree.Branch)
-	
-	// Create path line with proper styling
-	pathStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#6B7280"))
-	pathContent := pathStyle.Render("  " + worktree.Path)
+	mainContent := fmt.Sprintf("%s (%s)", filepath.Base(worktree.Path), worktreeRef(worktree))
 	
 	// Check if this worktree is being deleted
 	if m.deletingWorktree && worktree.Path == m.deletingPath {
 		deletingStyle := errorStyle.Copy().Strikethrough(true)
-		return deletingStyle.Render("🗑️  Deleting " + mainContent + "...")
+		return deletingStyle.Render(markers.Deleting + " Deleting " + mainContent + "...")
+	}
+	
+	// Highlight the characters matched by the current filter, if any
+	match := m.worktreeMatches[worktree.Path]
+	textStyle := lipgloss.NewStyle()
+	if selected {
+		textStyle = selectedTextStyle
+	}
+	ref := highlightMatches(worktree.Branch, match.branch, textStyle)
+	if worktree.Branch == "" {
+		// Detached worktrees show the tag or commit they were created at
+		ref = textStyle.Render("detached at " + worktreeRef(worktree))
+		if worktree.Tag != "" {
+			ref = textStyle.Render("detached at ") + highlightMatches(worktree.Tag, match.tag, textStyle)
+		}
+	}
+	mainContent = fmt.Sprintf("%s (%s)",
+		highlightMatches(filepath.Base(worktree.Path), baseNameMatches(worktree.Path, match.path), textStyle),
+		ref)
+	if worktree.Meta.Pinned {
+		mainContent = markers.Pinned + " " + mainContent
+	}
+	if m.marked[worktree.Path] {
+		mainContent = markers.Marked + " " + mainContent
+	}
+	if badge := m.renderPRBadge(worktree.Branch); badge != "" {
+		mainContent += "  " + badge
+	}
+	if expiry := renderExpiry(worktree.Meta, time.Now()); expiry != "" {
+		mainContent += "  " + expiry
+	}
+	if len(worktree.Meta.Labels) > 0 {
+		mainContent += "  " + renderLabels(worktree.Meta.Labels)
+	}
+	
+	// Create path line with proper styling
+	pathContent := "  " + highlightMatches(worktree.Path, match.path, pathStyle)
+	if len(match.head) > 0 {
+		pathContent += " " + highlightMatches(worktree.Head, match.head, pathStyle)
+	}
+	if summary := metaSummary(worktree.Meta, time.Now()); summary != "" {
+		pathContent += pathStyle.Render(" · " + summary)
+	}
+	if usage := m.renderDiskUsage(worktree.Path); usage != "" {
+		pathContent += pathStyle.Render(" · " + usage)
+	}
+	if status, _ := m.submodules.get(worktree.Path); status.String() != "" {
+		pathContent += pendingStyle.Render(" · " + status.String())
+	}
+	if pointers, _ := m.lfsPointers.get(worktree.Path); pointers > 0 {
+		pathContent += pendingStyle.Render(" · " + describeLFSPointers(pointers))
+	}
+	if note := noteFirstLine(worktree.Meta.Note); note != "" {
+		pathContent += "\n  " + highlightMatches(note, match.note, noteStyle)
 	}
 	
 	// Combine main content and path
 	var fullContent string
 	if selected {
-		fullContent = selectedItemStyle.Render("▶ " + mainContent) + "\n" + pathContent
+		fullContent = selectedItemStyle.Render(markers.Cursor + " " + mainContent) + "\n" + pathContent
 	} else {
 		fullContent = normalItemStyle.Render("  " + mainContent) + "\n" + pathContent
 	}
@@ -533,22 +1264,44 @@ func (m model) renderWorktreeItem(worktree Worktree, selected bool) string {
 	return fullContent
 }
 
+// baseNameMatches shifts match offsets within a full path so they index
+// into its base name, dropping any that fall in the directory part.
+func baseNameMatches(path string, indexes []int) []int {
+	offset := len(path) - len(filepath.Base(path))
+	var shifted []int
+	for _, i := range indexes {
+		if i >= offset {
+			shifted = append(shifted, i-offset)
+		}
+	}
+	return shifted
+}
+
 func (m model) renderBranchItem(branch Branch, selected bool) string {
 	var typeStyle lipgloss.Style
 	var typeLabel string
 	
-	if branch.Type == "local" {
+	switch branch.Type {
+	case "local":
 		typeStyle = branchTypeStyle
 		typeLabel = "local"
-	} else {
+	case "tag":
+		typeStyle = tagTypeStyle
+		typeLabel = "tag"
+	default:
 		typeStyle = remoteBranchTypeStyle
 		typeLabel = "remote"
 	}
 	
-	content := fmt.Sprintf("%s %s", typeStyle.Render("["+typeLabel+"]"), branch.Name)
+	nameStyle := lipgloss.NewStyle()
+	if selected {
+		nameStyle = selectedTextStyle
+	}
+	name := highlightMatches(branch.Name, m.branchMatches[branch.Name], nameStyle)
+	content := fmt.Sprintf("%s %s", typeStyle.Render("["+typeLabel+"]"), name)
 	
 	if selected {
-		return selectedItemStyle.Render("▶ " + content)
+		return selectedItemStyle.Render(markers.Cursor + " " + content)
 	}
 	return normalItemStyle.Render("  " + content)
 }
@@ -562,66 +1315,129 @@ func (m model) renderScrollIndicator(currentItems, totalItems int) string {
 	return helpStyle.Render(scrollInfo)
 }
 
-func (m *model) filterBranches() {
-	filterText := m.filterInput.Value()
-	if filterText == "" {
-		m.branches = m.allBranches
-		return
+func (m *model) startFilter() tea.Cmd {
+	m.filtering = true
+	if m.view == "worktrees" {
+		m.filterInput.Placeholder = "Fuzzy filter by path, branch or HEAD (remote:, author:, dirty:)..."
+	} else if m.view == "stashes" {
+		m.filterInput.Placeholder = "Fuzzy filter stashes by branch or message..."
+	} else if m.view == "tags" {
+		m.filterInput.Placeholder = "Fuzzy filter tags (author:)..."
+	} else {
+		m.filterInput.Placeholder = "Fuzzy filter branches (remote:, author:)..."
 	}
+	m.filterInput.SetValue("")
+	return m.filterInput.Focus()
+}
 
-	// Create a slice of branch names for fuzzy search
-	branchNames := make([]string, len(m.allBranches))
-	for i, branch := range m.allBranches {
-		branchNames[i] = branch.Name
+func (m *model) startNewBranch() tea.Cmd {
+	rules, err := loadNamingRules(m.repo, m.config)
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("%s %v", markers.Error, err)
+		return clearStatusAfterDelay()
 	}
-	
-	// Perform fuzzy search
-	matches := fuzzy.Find(filterText, branchNames)
-	
-	// Build filtered branches based on matches
-	filtered := make([]Branch, 0, len(matches))
-	for _, match := range matches {
-		filtered = append(filtered, m.allBranches[match.Index])
+	m.creatingBranch = true
+	m.newBranchInput.SetValue("")
+	m.newBranchError = ""
+	m.newBranchWarning = ""
+	m.newBranchRules = rules
+	m.newBranchDirs = loadWorktreeDirs(m.repo)
+	m.newBranchPrefix = 0
+	m.newBranchIssue = ""
+	m.promoteSource = ""
+	if prefix := m.currentPrefix(); prefix != "" {
+		m.newBranchInput.SetValue(prefix)
+		m.newBranchInput.CursorEnd()
 	}
-	
-	m.branches = filtered
-	
-	// Reset cursor if it's out of bounds
-	if m.cursor >= len(m.branches) {
-		m.cursor = 0
+	return m.newBranchInput.Focus()
+}
+
+// views are the tabs, in the order the switch view key cycles through them.
+var views = []string{"worktrees", "branches", "tags", "stashes"}
+
+func nextView(view string) string {
+	for i, v := range views {
+		if v == view {
+			return views[(i+1)%len(views)]
+		}
 	}
+	return views[0]
+}
+
+func (m *model) switchView(view string) {
+	m.view = view
+	m.focusWorktree = ""
+	m.clearFilter()
+	m.cursor = 0
+	m.scrollOffset = 0
+}
+
+func (m *model) clearFilter() {
+	m.filtering = false
+	m.filterInput.SetValue("")
+	m.filterInput.Blur()
+	m.worktrees = m.allWorktrees
+	m.branches = m.allBranches
+	m.tags = m.allTags
+	m.stashes = m.allStashes
+	m.worktreeMatches = nil
+	m.branchMatches = nil
+}
+
+func (m model) listLen() int {
+	if m.view == "worktrees" {
+		return len(m.worktrees)
+	}
+	if m.view == "stashes" {
+		return len(m.stashes)
+	}
+	if m.view == "tags" {
+		return len(m.tags)
+	}
+	return len(m.branches)
 }
 
 func (m *model) adjustScrollOffset() {
 	if m.view == "worktrees" {
-		// For worktrees view, each item takes 2 lines
-		maxWorktreesInView := m.viewportHeight / 2
+		// Worktrees take two or three lines each, so scroll until the
+		// cursor's item fits
 		if m.cursor < m.scrollOffset {
 			m.scrollOffset = m.cursor
-		} else if m.cursor >= m.scrollOffset+maxWorktreesInView {
-			m.scrollOffset = m.cursor - maxWorktreesInView + 1
+		}
+		for m.scrollOffset < m.cursor && m.cursor >= m.scrollOffset+m.worktreesFitting(m.scrollOffset) {
+			m.scrollOffset++
 		}
 	} else {
-		// For branches view, each item takes 1 line
+		// Branches and stashes take 1 line each
+		height := m.listHeight()
 		if m.cursor < m.scrollOffset {
 			m.scrollOffset = m.cursor
-		} else if m.cursor >= m.scrollOffset+m.viewportHeight {
-			m.scrollOffset = m.cursor - m.viewportHeight + 1
+		} else if m.cursor >= m.scrollOffset+height {
+			m.scrollOffset = m.cursor - height + 1
 		}
 	}
 }
 
+// listHeight is the number of lines the branch or stash list may take.
+func (m model) listHeight() int {
+	if m.view == "stashes" {
+		return m.stashListHeight()
+	}
+	return m.viewportHeight
+}
+
 func (m *model) getViewportRange(totalItems int) (int, int) {
-	if totalItems <= m.viewportHeight {
+	height := m.listHeight()
+	if totalItems <= height {
 		return 0, totalItems
 	}
 	
 	start := m.scrollOffset
-	end := start + m.viewportHeight
+	end := start + height
 	
 	if end > totalItems {
 		end = totalItems
-		start = end - m.viewportHeight
+		start = end - height
 		if start < 0 {
 			start = 0
 		}
@@ -630,20 +1446,47 @@ func (m *model) getViewportRange(totalItems int) (int, int) {
 	return start, end
 }
 
-func (m *model) getViewportRangeForWorktrees(totalItems int, maxItemsInView int) (int, int) {
-	if totalItems <= maxItemsInView {
-		return 0, totalItems
+// worktreeHeight is the number of lines a worktree takes in the list: name
+// and path, plus the first line of its note.
+func worktreeHeight(worktree Worktree) int {
+	if noteFirstLine(worktree.Meta.Note) != "" {
+		return 3
 	}
-	
+	return 2
+}
+
+// worktreesFitting counts the worktrees from start on that fit in the
+// viewport, always at least one.
+func (m model) worktreesFitting(start int) int {
+	used, count := 0, 0
+	for i := start; i < len(m.worktrees); i++ {
+		used += worktreeHeight(m.worktrees[i])
+		if used > m.viewportHeight && count > 0 {
+			break
+		}
+		count++
+	}
+	return count
+}
+
+func (m *model) getViewportRangeForWorktrees(totalItems int) (int, int) {
 	start := m.scrollOffset
-	end := start + maxItemsInView
-	
+	if start > totalItems {
+		start = totalItems
+	}
+	end := start + m.worktreesFitting(start)
 	if end > totalItems {
 		end = totalItems
-		start = end - maxItemsInView
-		if start < 0 {
-			start = 0
-		}
+	}
+	
+	// At the bottom, pull in earlier worktrees while there is room
+	used := 0
+	for i := start; i < end; i++ {
+		used += worktreeHeight(m.worktrees[i])
+	}
+	for end == totalItems && start > 0 && used+worktreeHeight(m.worktrees[start-1]) <= m.viewportHeight {
+		start--
+		used += worktreeHeight(m.worktrees[start])
 	}
 	
 	return start, end
@@ -657,21 +1500,36 @@ func isValidBranchChar(s string) bool {
 	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '-' || c == '_' || c == '/' || c == '.'
 }
 
-func runNonInteractive(listWorktrees, listBranches *bool, createWorktreeFlag, deleteWorktreeFlag, createNewBranch *string) {
+func runNonInteractive(cfg Config, listWorktrees, listBranches *bool, createWorktreeFlag, deleteWorktreeFlag, createNewBranch, sparseFlag *string) {
+	// New worktrees use the --sparse profile, or the configured default
+	var opts createOptions
+	if *createWorktreeFlag != "" || *createNewBranch != "" {
+		name := *sparseFlag
+		if name == "" {
+			name = defaultSparse(".", cfg)
+		}
+		var err error
+		opts, err = newCreateOptions(".", cfg, name)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	
 	if *listWorktrees {
-		worktrees, err := getWorktrees()
+		worktrees, err := getWorktrees(".")
 		if err != nil {
 			fmt.Printf("Error getting worktrees: %v\n", err)
 			os.Exit(1)
 		}
 		fmt.Println("Worktrees:")
 		for _, wt := range worktrees {
-			fmt.Printf("  %s (%s)\n", wt.Path, wt.Branch)
+			fmt.Printf("  %s (%s)\n", wt.Path, worktreeRef(wt))
 		}
 	}
 
 	if *listBranches {
-		branches, err := getBranches()
+		branches, err := getBranches(".")
 		if err != nil {
 			fmt.Printf("Error getting branches: %v\n", err)
 			os.Exit(1)
@@ -684,11 +1542,15 @@ func runNonInteractive(listWorktrees, listBranches *bool, createWorktreeFlag, de
 
 	if *createWorktreeFlag != "" {
 		// Find the branch
-		branches, err := getBranches()
+		branches, err := getBranches(".")
 		if err != nil {
 			fmt.Printf("Error getting branches: %v\n", err)
 			os.Exit(1)
 		}
+		// Tags can be checked out too, in a detached worktree
+		if tags, err := getTags("."); err == nil {
+			branches = append(branches, tags...)
+		}
 		
 		var targetBranch *Branch
 		for _, branch := range branches {
@@ -703,17 +1565,21 @@ func runNonInteractive(listWorktrees, listBranches *bool, createWorktreeFlag, de
 			os.Exit(1)
 		}
 		
-		err = createWorktree(*targetBranch)
-		if err != nil {
+		path, err := createWorktree(".", *targetBranch, opts)
+		if err = warnMetadata(err); err != nil {
 			fmt.Printf("Error creating worktree: %v\n", err)
 			os.Exit(1)
 		}
+		if _, err := setupWorktree(cfg, path); err != nil {
+			fmt.Printf("Error setting up worktree: %v\n", err)
+			os.Exit(1)
+		}
 		fmt.Printf("Successfully created worktree for branch '%s'\n", targetBranch.Name)
 	}
 
 	if *deleteWorktreeFlag != "" {
 		// Find the worktree
-		worktrees, err := getWorktrees()
+		worktrees, err := getWorktrees(".")
 		if err != nil {
 			fmt.Printf("Error getting worktrees: %v\n", err)
 			os.Exit(1)
@@ -732,7 +1598,7 @@ func runNonInteractive(listWorktrees, listBranches *bool, createWorktreeFlag, de
 			os.Exit(1)
 		}
 		
-		err = deleteWorktree(*targetWorktree)
+		err = warnMetadata(deleteWorktree(".", *targetWorktree))
 		if err != nil {
 			fmt.Printf("Error deleting worktree: %v\n", err)
 			os.Exit(1)
@@ -741,11 +1607,29 @@ func runNonInteractive(listWorktrees, listBranches *bool, createWorktreeFlag, de
 	}
 
 	if *createNewBranch != "" {
-		err := createNewBranchWorktree(*createNewBranch)
+		branches, err := getBranches(".")
 		if err != nil {
+			fmt.Printf("Error getting branches: %v\n", err)
+			os.Exit(1)
+		}
+		rules, err := loadNamingRules(".", cfg)
+		if err != nil {
+			fmt.Printf("Error loading branch naming rules: %v\n", err)
+			os.Exit(1)
+		}
+		if err := checkNewBranch(".", *createNewBranch, branches, rules); err != nil {
+			fmt.Printf("Invalid branch name '%s': %v\n", *createNewBranch, err)
+			os.Exit(1)
+		}
+		path, err := createNewBranchWorktree(".", *createNewBranch, opts)
+		if err = warnMetadata(err); err != nil {
 			fmt.Printf("Error creating new branch and worktree: %v\n", err)
 			os.Exit(1)
 		}
+		if _, err := setupWorktree(cfg, path); err != nil {
+			fmt.Printf("Error setting up worktree: %v\n", err)
+			os.Exit(1)
+		}
 		fmt.Printf("Successfully created new branch '%s' and worktree\n", *createNewBranch)
 	}
 }
@@ -757,7 +1641,10 @@ func main() {
 	createWorktreeFlag := flag.String("create-worktree", "", "Create a worktree for the specified branch")
 	deleteWorktreeFlag := flag.String("delete-worktree", "", "Delete the worktree at the specified path")
 	createNewBranch := flag.String("create-new-branch", "", "Create a new branch and worktree")
+	sparseFlag := flag.String("sparse", "", "Sparse-checkout profile for --create-worktree and --create-new-branch (\"full\" for none)")
 	nonInteractive := flag.Bool("non-interactive", false, "Run in non-interactive mode")
+	noColor := flag.Bool("no-color", false, "Disable colors (same as setting NO_COLOR)")
+	ascii := flag.Bool("ascii", false, "Use ASCII status markers instead of emoji")
 	help := flag.Bool("help", false, "Show help message")
 
 	flag.Parse()
@@ -771,11 +1658,18 @@ func main() {
 		fmt.Println("  wtree --create-worktree <branch>   Create a worktree for the specified branch")
 		fmt.Println("  wtree --delete-worktree <path>     Delete the worktree at the specified path")
 		fmt.Println("  wtree --create-new-branch <name>   Create a new branch and worktree")
+		fmt.Println("  wtree --sparse <profile>    Sparse-checkout profile for the worktrees created above")
+		fmt.Println("  wtree --no-color            Disable colors (NO_COLOR is also honored)")
+		fmt.Println("  wtree --ascii               Use ASCII status markers instead of emoji")
 		fmt.Println("  wtree --help                Show this help message")
+		for _, usage := range subcommandUsages() {
+			fmt.Printf("  %s\n", usage)
+		}
 		fmt.Println("\nExamples:")
 		fmt.Println("  wtree --create-worktree feature/new-feature")
 		fmt.Println("  wtree --delete-worktree ../playground-feature-new-feature")
 		fmt.Println("  wtree --create-new-branch bugfix/fix-issue")
+		fmt.Println("  wtree pr 1234")
 		return
 	}
 
@@ -788,20 +1682,84 @@ func main() {
 	}
 
 	// Check if we're in a git repository
-	if !isGitRepository() {
+	if !isGitRepository(".") {
 		fmt.Println("Error: wtree must be run from within a git repository")
 		fmt.Println("Please navigate to a git repository and try again.")
 		os.Exit(1)
 	}
 
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	
+	// Handle subcommands such as `wtree pr 123`
+	if args := flag.Args(); len(args) > 0 {
+		runSubcommand(cfg, args)
+		return
+	}
+	
 	// Handle non-interactive commands
 	if *listWorktrees || *listBranches || *createWorktreeFlag != "" || *deleteWorktreeFlag != "" || *createNewBranch != "" || *nonInteractive {
-		runNonInteractive(listWorktrees, listBranches, createWorktreeFlag, deleteWorktreeFlag, createNewBranch)
+		runNonInteractive(cfg, listWorktrees, listBranches, createWorktreeFlag, deleteWorktreeFlag, createNewBranch, sparseFlag)
 		return
 	}
 
-	// Run interactive mode with alternate screen
-	p := tea.NewProgram(initialModel(), tea.WithAltScreen())
+	if err := setupAppearance(cfg, *noColor, *ascii); err != nil {
+		fmt.Printf("Error in theme config: %v\n", err)
+		os.Exit(1)
+	}
+	
+	if _, err := cfg.Refresh.interval(); err != nil {
+		fmt.Printf("Error in config: %v\n", err)
+		os.Exit(1)
+	}
+	
+	if _, err := cfg.Scratch.ttl(); err != nil {
+		fmt.Printf("Error in config: %v\n", err)
+		os.Exit(1)
+	}
+	
+	if _, err := cfg.Forge.cacheTTL(); err != nil {
+		fmt.Printf("Error in config: %v\n", err)
+		os.Exit(1)
+	}
+	
+	if !validSortOrder(cfg.Sort) {
+		fmt.Printf("Error in config: unknown sort order %q (expected recent or git)\n", cfg.Sort)
+		os.Exit(1)
+	}
+	
+	for _, rules := range cfg.Naming {
+		if err := rules.validate(); err != nil {
+			fmt.Printf("Error in config: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	
+	for _, sparse := range cfg.Sparse {
+		if err := sparse.validate(); err != nil {
+			fmt.Printf("Error in config: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	
+	keys, err := newKeyMap(cfg.Keys)
+	if err != nil {
+		fmt.Printf("Error in key bindings config: %v\n", err)
+		os.Exit(1)
+	}
+	
+	m := initialModel()
+	m.keys = keys
+	m.config = cfg
+	m.forge = loadForgeProvider(m.repo, cfg.Forge)
+	m.loadIssueTracker(cfg.Tracker)
+	m.newSparse = defaultSparse(m.repo, cfg)
+	
+	// Run interactive mode with alternate screen and mouse support
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
 	if _, err := p.Run(); err != nil {
 		log.Fatal(err)
 	}
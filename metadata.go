@@ -0,0 +1,339 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// metadataVersion is the schema version written to metadata.json. Bump it
+// and extend migrateMetadata when the layout changes incompatibly.
+const metadataVersion = 1
+
+// WorktreeMeta is what wtree remembers about a worktree beyond what git
+// records.
+type WorktreeMeta struct {
+	CreatedAt  time.Time `json:"created_at,omitzero"`
+	Owner      string    `json:"owner,omitempty"`
+	Issue      string    `json:"issue,omitempty"`
+	Note       string    `json:"note,omitempty"`
+	Labels     []string  `json:"labels,omitempty"`
+	LastOpened time.Time `json:"last_opened,omitzero"`
+	Pinned     bool      `json:"pinned,omitempty"`
+	Scratch    bool      `json:"scratch,omitempty"`
+	ExpiresAt  time.Time `json:"expires_at,omitzero"`
+	Sparse     string    `json:"sparse,omitempty"` // sparse-checkout profile, empty for a full checkout
+}
+
+// expired reports whether the worktree outlived its TTL.
+func (meta WorktreeMeta) expired(now time.Time) bool {
+	return !meta.ExpiresAt.IsZero() && now.After(meta.ExpiresAt)
+}
+
+// metadataFile is the on-disk layout, keyed by worktree path.
+type metadataFile struct {
+	Version   int                     `json:"version"`
+	Worktrees map[string]WorktreeMeta `json:"worktrees"`
+}
+
+// metadataPath lives in the common git dir so every worktree of the
+// repository shares it.
+func metadataPath(repo string) (string, error) {
+	commonDir, err := getGitCommonDir(repo)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(commonDir, "wtree", "metadata.json"), nil
+}
+
+// migrateMetadata upgrades file to metadataVersion. Files written by a
+// newer wtree are rejected rather than risk dropping fields on save.
+func migrateMetadata(file *metadataFile) error {
+	if file.Version > metadataVersion {
+		return fmt.Errorf("metadata version %d is newer than this wtree supports (%d)", file.Version, metadataVersion)
+	}
+	// Version 0 only knew scratch expiry, which version 1 keeps as is
+	file.Version = metadataVersion
+	return nil
+}
+
+// loadMetadata reads the metadata of all worktrees. A missing file yields
+// an empty map.
+func loadMetadata(repo string) (map[string]WorktreeMeta, error) {
+	path, err := metadataPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[string]WorktreeMeta), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file metadataFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid metadata %s: %w", path, err)
+	}
+	if err := migrateMetadata(&file); err != nil {
+		return nil, err
+	}
+	if file.Worktrees == nil {
+		file.Worktrees = make(map[string]WorktreeMeta)
+	}
+	return file.Worktrees, nil
+}
+
+// saveMetadata writes worktrees to the metadata file at path. Callers hold
+// the metadata lock.
+func saveMetadata(path string, worktrees map[string]WorktreeMeta) error {
+	data, err := json.MarshalIndent(metadataFile{Version: metadataVersion, Worktrees: worktrees}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves half a file.
+	// Its name is unique, so concurrent writers never share it.
+	tmp, err := os.CreateTemp(filepath.Dir(path), "metadata-*.json.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// metadataMu serializes metadata updates within this process; the lock
+// file serializes them across wtree processes.
+var metadataMu sync.Mutex
+
+const (
+	metadataLockTimeout = 5 * time.Second
+	// staleLockAge is when a lock file is taken to be left behind by a
+	// wtree that crashed while holding it.
+	staleLockAge = 30 * time.Second
+)
+
+// lockMetadata takes the lock file next to the metadata at path, waiting
+// for another wtree to release it, and returns the function releasing it.
+func lockMetadata(path string) (func(), error) {
+	lock := path + ".lock"
+	deadline := time.Now().Add(metadataLockTimeout)
+	for {
+		file, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			file.Close()
+			return func() { os.Remove(lock) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if info, err := os.Stat(lock); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(lock)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("metadata is locked by another wtree; remove %s if none is running", lock)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// updateMetadata loads the metadata, lets update change it and saves it,
+// holding the metadata lock throughout so concurrent updates aren't lost.
+func updateMetadata(repo string, update func(worktrees map[string]WorktreeMeta)) error {
+	metadataMu.Lock()
+	defer metadataMu.Unlock()
+
+	path, err := metadataPath(repo)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	unlock, err := lockMetadata(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	worktrees, err := loadMetadata(repo)
+	if err != nil {
+		return err
+	}
+	update(worktrees)
+	return saveMetadata(path, worktrees)
+}
+
+// getWorktreeMeta returns the metadata of the worktree at path.
+func getWorktreeMeta(repo, path string) (WorktreeMeta, error) {
+	worktrees, err := loadMetadata(repo)
+	if err != nil {
+		return WorktreeMeta{}, err
+	}
+	return worktrees[path], nil
+}
+
+// editWorktreeMeta applies edit to the metadata of the worktree at path.
+func editWorktreeMeta(repo, path string, edit func(meta *WorktreeMeta)) error {
+	return updateMetadata(repo, func(worktrees map[string]WorktreeMeta) {
+		meta := worktrees[path]
+		edit(&meta)
+		worktrees[path] = meta
+	})
+}
+
+// forgetWorktreeMeta drops the metadata of removed worktrees.
+func forgetWorktreeMeta(repo string, paths ...string) error {
+	return updateMetadata(repo, func(worktrees map[string]WorktreeMeta) {
+		for _, path := range paths {
+			delete(worktrees, path)
+		}
+	})
+}
+
+// pruneMetadata drops metadata of worktrees git no longer knows about.
+func pruneMetadata(repo string, worktrees []Worktree) error {
+	known := make(map[string]bool, len(worktrees))
+	for _, worktree := range worktrees {
+		known[worktree.Path] = true
+	}
+	return updateMetadata(repo, func(stored map[string]WorktreeMeta) {
+		for path := range stored {
+			if !known[path] {
+				delete(stored, path)
+			}
+		}
+	})
+}
+
+// markOpened records that the worktree at path was just opened.
+func markOpened(path string) error {
+	return editWorktreeMeta(path, path, func(meta *WorktreeMeta) {
+		meta.LastOpened = time.Now()
+	})
+}
+
+// metaSummary is the one-line summary of meta shown under a worktree.
+func metaSummary(meta WorktreeMeta, now time.Time) string {
+	var parts []string
+	if meta.Issue != "" {
+		parts = append(parts, meta.Issue)
+	}
+	if meta.Owner != "" {
+		parts = append(parts, meta.Owner)
+	}
+	if meta.Sparse != "" {
+		parts = append(parts, "sparse: "+meta.Sparse)
+	}
+	if !meta.LastOpened.IsZero() {
+		parts = append(parts, "opened "+humanDuration(now.Sub(meta.LastOpened))+" ago")
+	} else if !meta.CreatedAt.IsZero() {
+		parts = append(parts, "created "+humanDuration(now.Sub(meta.CreatedAt))+" ago")
+	}
+	return strings.Join(parts, " · ")
+}
+
+// humanDuration rounds d down to whole days, hours or minutes.
+func humanDuration(d time.Duration) string {
+	switch {
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dm", int(d.Minutes()))
+}
+
+// metadataError reports that a worktree was created or removed but its
+// metadata couldn't be updated. Callers keep the result and report the
+// error as a warning rather than a failure.
+type metadataError struct{ err error }
+
+func (e metadataError) Error() string {
+	return "worktree metadata not saved: " + e.err.Error()
+}
+
+func (e metadataError) Unwrap() error { return e.err }
+
+// isMetadataError reports whether err is only about the metadata.
+func isMetadataError(err error) bool {
+	var metaErr metadataError
+	return errors.As(err, &metaErr)
+}
+
+// isWarning reports whether err is about a step that failed after the
+// operation itself succeeded, so it is reported as a warning.
+func isWarning(err error) bool {
+	var kept stashKeptError
+	return isMetadataError(err) || errors.As(err, &kept)
+}
+
+// warningMsg delivers msg along with a warning about a step that failed
+// without failing the operation.
+type warningMsg struct {
+	msg     tea.Msg
+	warning string
+}
+
+// resultMsg is what a command reports for an operation that returned err:
+// the error when it failed, otherwise msg, with a warning when only a step
+// like saving the metadata failed.
+func resultMsg(msg tea.Msg, err error) tea.Msg {
+	switch {
+	case isWarning(err):
+		return warningMsg{msg: msg, warning: err.Error()}
+	case err != nil:
+		return err
+	}
+	return msg
+}
+
+// warnMetadata prints a metadata error, or another warning, as a warning
+// for the command line and returns any other error.
+func warnMetadata(err error) error {
+	if isWarning(err) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return nil
+	}
+	return err
+}
+
+// recordCreated stores the creation time and owner of a worktree wtree
+// just created. Failures are metadataErrors.
+func recordCreated(path string) error {
+	owner := ""
+	if output, err := exec.Command("git", "-C", path, "config", "user.name").Output(); err == nil {
+		owner = strings.TrimSpace(string(output))
+	}
+	err := editWorktreeMeta(path, path, func(meta *WorktreeMeta) {
+		meta.CreatedAt = time.Now()
+		if meta.Owner == "" {
+			meta.Owner = owner
+		}
+	})
+	if err != nil {
+		return metadataError{err}
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMetadataLifecycle(t *testing.T) {
+	repo := newTestRepo(t)
+	t.Chdir(repo)
+	t.Setenv("GIT_CONFIG_PARAMETERS", "'user.name=Jane Doe'")
+
+	path, err := createNewBranchWorktree(".", "feature/meta", createOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta, err := getWorktreeMeta(".", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.CreatedAt.IsZero() || meta.Owner != "Jane Doe" {
+		t.Errorf("Expected creation time and owner recorded, got %+v", meta)
+	}
+
+	if err := editWorktreeMeta(".", path, func(meta *WorktreeMeta) { meta.Issue = "ABC-1" }); err != nil {
+		t.Fatal(err)
+	}
+	worktrees, err := getWorktrees(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, worktree := range worktrees {
+		if worktree.Path == path {
+			found = true
+			if worktree.Meta.Issue != "ABC-1" {
+				t.Errorf("Expected the listed worktree to carry its metadata, got %+v", worktree.Meta)
+			}
+			if err := deleteWorktree(".", worktree); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("Expected %s among the worktrees", path)
+	}
+
+	stored, err := loadMetadata(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := stored[path]; ok {
+		t.Error("Expected deleting the worktree to drop its metadata")
+	}
+}
+
+func TestPruneWorktreesDropsMetadata(t *testing.T) {
+	repo := newTestRepo(t)
+	t.Chdir(repo)
+
+	path, err := createNewBranchWorktree(".", "feature/gone", createOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := pruneWorktrees("."); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := loadMetadata(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := stored[path]; ok {
+		t.Error("Expected pruning to drop metadata of the missing worktree")
+	}
+}
+
+func TestMetadataVersioning(t *testing.T) {
+	repo := newTestRepo(t)
+	t.Chdir(repo)
+
+	path, err := metadataPath(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Files from before versioning are upgraded in place
+	legacy := `{"worktrees": {"/tmp/scratch": {"scratch": true}}}`
+	if err := os.WriteFile(path, []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := loadMetadata(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored["/tmp/scratch"].Scratch {
+		t.Errorf("Expected legacy metadata to load, got %+v", stored)
+	}
+
+	future := `{"version": 99, "worktrees": {}}`
+	if err := os.WriteFile(path, []byte(future), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadMetadata("."); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("Expected a newer schema to be rejected, got %v", err)
+	}
+}
+
+func TestConcurrentMetadataUpdates(t *testing.T) {
+	repo := newTestRepo(t)
+	t.Chdir(repo)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := editWorktreeMeta(".", fmt.Sprintf("/wt/%d", i), func(meta *WorktreeMeta) { meta.Pinned = true }); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	stored, err := loadMetadata(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 20 {
+		t.Errorf("Expected every update to be kept, got %d entries", len(stored))
+	}
+	path, _ := metadataPath(".")
+	leftovers, _ := filepath.Glob(filepath.Join(filepath.Dir(path), "*.tmp"))
+	if _, err := os.Stat(path + ".lock"); err == nil || len(leftovers) > 0 {
+		t.Errorf("Expected the lock and temporary files to be removed, got %v", leftovers)
+	}
+
+	// A lock left behind by a crashed wtree is taken over once it is stale
+	if err := os.WriteFile(path+".lock", nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stale := time.Now().Add(-time.Hour)
+	os.Chtimes(path+".lock", stale, stale)
+	if err := editWorktreeMeta(".", "/wt/0", func(meta *WorktreeMeta) { meta.Pinned = false }); err != nil {
+		t.Errorf("Expected a stale lock to be taken over, got %v", err)
+	}
+}
+
+func TestMetadataErrorsAreWarnings(t *testing.T) {
+	repo := newTestRepo(t)
+	t.Chdir(repo)
+
+	path, err := createNewBranchWorktree(".", "feature/meta", createOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	metaPath, _ := metadataPath(".")
+	if err := os.WriteFile(metaPath, []byte("not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The worktree is still created and removed, and the metadata error reported
+	other, err := createNewBranchWorktree(".", "feature/other", createOptions{})
+	if !isMetadataError(err) || other == "" {
+		t.Errorf("Expected a metadata error with the new path, got %q, %v", other, err)
+	}
+	err = deleteWorktree(".", Worktree{Path: path})
+	if !isMetadataError(err) {
+		t.Errorf("Expected only a metadata error, got %v", err)
+	}
+	if _, statErr := os.Stat(path); !os.IsNotExist(statErr) {
+		t.Errorf("Expected the worktree to be removed, got %v", statErr)
+	}
+
+	m := initialModel()
+	updated, _ := m.Update(resultMsg(worktreeDeletedMsg{}, err))
+	m = updated.(model)
+	if m.deletingWorktree || !strings.Contains(m.statusMessage, "metadata not saved") {
+		t.Errorf("Expected the deletion handled with a warning, got %q", m.statusMessage)
+	}
+}
+
+func TestMetaSummary(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		meta     WorktreeMeta
+		expected string
+	}{
+		{WorktreeMeta{}, ""},
+		{WorktreeMeta{CreatedAt: now.Add(-3 * time.Hour)}, "created 3h ago"},
+		{WorktreeMeta{Issue: "ABC-1", Owner: "jane", CreatedAt: now.Add(-72 * time.Hour), LastOpened: now.Add(-5 * time.Minute)}, "ABC-1 · jane · opened 5m ago"},
+	}
+	for _, test := range tests {
+		if summary := metaSummary(test.meta, now); summary != test.expected {
+			t.Errorf("metaSummary(%+v) = %q, expected %q", test.meta, summary, test.expected)
+		}
+	}
+}
+
+func TestMetaFormApply(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	form := newMetaForm(Worktree{Path: "/tmp/wt", Meta: WorktreeMeta{Owner: "jane", Scratch: true}})
+	form.inputs[1].SetValue(" ABC-1 ")
+	form.inputs[2].SetValue("48h")
+	form.inputs[3].SetValue("wip, review,, wip")
+
+	meta := WorktreeMeta{Scratch: true}
+	if err := form.apply(&meta, now); err != nil {
+		t.Fatal(err)
+	}
+	expected := WorktreeMeta{Owner: "jane", Issue: "ABC-1", Scratch: true, ExpiresAt: now.Add(48 * time.Hour), Labels: []string{"wip", "review"}}
+	if !reflect.DeepEqual(meta, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, meta)
+	}
+
+	form.inputs[2].SetValue("2026-03-05 09:30")
+	if err := form.apply(&meta, now); err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 3, 5, 9, 30, 0, 0, time.Local); !meta.ExpiresAt.Equal(want) {
+		t.Errorf("Expected expiry %s, got %s", want, meta.ExpiresAt)
+	}
+
+	form.inputs[2].SetValue("")
+	if err := form.apply(&meta, now); err != nil || !meta.ExpiresAt.IsZero() {
+		t.Errorf("Expected an empty expiry to clear it, got %s (%v)", meta.ExpiresAt, err)
+	}
+
+	form.inputs[2].SetValue("next week")
+	if err := form.apply(&meta, now); err == nil {
+		t.Error("Expected an invalid expiry to be rejected")
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// expiryLayout is how expiry times are shown and typed in the form.
+const expiryLayout = "2006-01-02 15:04"
+
+var metaFieldLabels = []string{"Owner", "Issue", "Expires", "Labels"}
+
+// metaForm edits the stored metadata of one worktree, one input per field
+// in the order of metaFieldLabels.
+type metaForm struct {
+	path   string
+	inputs []textinput.Model
+	focus  int
+}
+
+func newMetaForm(worktree Worktree) metaForm {
+	values := []string{worktree.Meta.Owner, worktree.Meta.Issue, "", strings.Join(worktree.Meta.Labels, ", ")}
+	if !worktree.Meta.ExpiresAt.IsZero() {
+		values[2] = worktree.Meta.ExpiresAt.Local().Format(expiryLayout)
+	}
+	placeholders := []string{"Who works here", "Linked issue", "Date (" + expiryLayout + ") or duration (48h)", "Comma separated"}
+
+	form := metaForm{path: worktree.Path}
+	for i, value := range values {
+		input := textinput.New()
+		input.Placeholder = placeholders[i]
+		input.CharLimit = 100
+		input.Width = 40
+		input.SetValue(value)
+		form.inputs = append(form.inputs, input)
+	}
+	return form
+}
+
+// move focuses the next (delta 1) or previous (delta -1) field.
+func (f *metaForm) move(delta int) tea.Cmd {
+	f.inputs[f.focus].Blur()
+	f.focus = (f.focus + delta + len(f.inputs)) % len(f.inputs)
+	return f.inputs[f.focus].Focus()
+}
+
+// parseExpiry accepts an absolute time in expiryLayout or a duration from
+// now. An empty value clears the expiry.
+func parseExpiry(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(d), nil
+	}
+	if t, err := time.ParseInLocation(expiryLayout, value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("expiry must look like %q or a duration such as 48h", expiryLayout)
+}
+
+// apply copies the form values into meta.
+func (f metaForm) apply(meta *WorktreeMeta, now time.Time) error {
+	expiresAt, err := parseExpiry(f.inputs[2].Value(), now)
+	if err != nil {
+		return err
+	}
+	meta.Owner = strings.TrimSpace(f.inputs[0].Value())
+	meta.Issue = strings.TrimSpace(f.inputs[1].Value())
+	meta.ExpiresAt = expiresAt
+	meta.Labels = parseLabels(f.inputs[3].Value())
+	return nil
+}
+
+type metadataSavedMsg struct{ path string }
+
+func saveMetaFormCmd(repo string, form metaForm) tea.Cmd {
+	return func() tea.Msg {
+		var applyErr error
+		err := editWorktreeMeta(repo, form.path, func(meta *WorktreeMeta) {
+			applyErr = form.apply(meta, time.Now())
+		})
+		if applyErr != nil {
+			return applyErr
+		}
+		if err != nil {
+			return err
+		}
+		return metadataSavedMsg{path: form.path}
+	}
+}
+
+func (m *model) startMetaForm(worktree Worktree) tea.Cmd {
+	m.editingMeta = true
+	m.metaForm = newMetaForm(worktree)
+	return m.metaForm.inputs[0].Focus()
+}
+
+func (m *model) closeMetaForm() {
+	m.editingMeta = false
+	m.metaForm = metaForm{}
+}
+
+func (m model) confirmMetaForm() (model, tea.Cmd) {
+	// Validate before saving so a typo keeps the form open
+	if err := m.metaForm.apply(&WorktreeMeta{}, time.Now()); err != nil {
+		m.statusMessage = fmt.Sprintf("%s %v", markers.Error, err)
+		return m, clearStatusAfterDelay()
+	}
+	form := m.metaForm
+	m.closeMetaForm()
+	return m, saveMetaFormCmd(m.repo, form)
+}
+
+func (m model) renderMetaForm() string {
+	var content strings.Builder
+	content.WriteString(inputStyle.Render("Details of " + filepath.Base(m.metaForm.path)))
+	content.WriteString("\n")
+	for i, input := range m.metaForm.inputs {
+		label := fmt.Sprintf("%-8s", metaFieldLabels[i]+":")
+		if i == m.metaForm.focus {
+			content.WriteString(selectedItemStyle.Render(markers.Cursor + " " + label))
+		} else {
+			content.WriteString(normalItemStyle.Render("  " + label))
+		}
+		content.WriteString(" ")
+		content.WriteString(input.View())
+		content.WriteString("\n")
+	}
+	return content.String()
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// doubleClickInterval is the longest gap between two clicks on the same
+// row that still counts as a double-click.
+const doubleClickInterval = 400 * time.Millisecond
+
+func (m model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	// Text entry and the overlays have no clickable content
+	if m.showHelp || m.paletteOpen || m.creatingBranch || m.enteringPR || m.enteringCommit || m.editingMeta || m.editingNote || m.movingChanges || m.applyingStash || m.choosingSparse || m.confirmingClean || m.confirmingDrop {
+		return m, nil
+	}
+
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.scrollBy(-1)
+		return m, nil
+	case tea.MouseButtonWheelDown:
+		m.scrollBy(1)
+		return m, nil
+	case tea.MouseButtonLeft:
+		if msg.Action != tea.MouseActionPress {
+			return m, nil
+		}
+	default:
+		return m, nil
+	}
+
+	if msg.Y == 0 {
+		if view := m.tabAt(msg.X); view != "" && view != m.view {
+			m.switchView(view)
+		}
+		return m, nil
+	}
+
+	index := m.itemAt(msg.Y)
+	if index < 0 {
+		return m, nil
+	}
+
+	now := time.Now()
+	isDoubleClick := index == m.lastClickIndex && now.Sub(m.lastClickAt) <= doubleClickInterval
+	m.cursor = index
+	m.lastClickIndex = index
+	m.lastClickAt = now
+
+	if isDoubleClick {
+		// Reset so a third click doesn't count as another double-click
+		m.lastClickAt = time.Time{}
+		return m.activateSelection()
+	}
+	return m, nil
+}
+
+// tabAt returns the view whose tab covers column x of the header.
+func (m model) tabAt(x int) string {
+	right := 0
+	for _, view := range views {
+		right += lipgloss.Width(m.renderTab(view))
+		if x < right {
+			return view
+		}
+	}
+	return ""
+}
+
+// listTop returns the screen row of the first list item. It mirrors the
+// layout produced by View.
+func (m model) listTop() int {
+	// Header and the blank line below it
+	top := 2
+	if m.renderStatus() != "" {
+		top += 2
+	}
+	if m.view == "worktrees" {
+		if m.filtering || m.enteringPR || m.enteringCommit || m.movingChanges || m.choosingSparse || m.confirmingClean {
+			top++
+		}
+	} else if m.view == "stashes" {
+		if m.filtering || m.applyingStash || m.confirmingDrop {
+			top++
+		}
+	} else {
+		// Input line, or a blank line when no input is active
+		top++
+	}
+	return top
+}
+
+func (m model) itemHeight(index int) int {
+	if m.view != "worktrees" {
+		return 1
+	}
+	// Rows being deleted collapse to a single line
+	if m.deletingWorktree && m.worktrees[index].Path == m.deletingPath {
+		return 1
+	}
+	return worktreeHeight(m.worktrees[index])
+}
+
+// itemAt returns the index of the list item drawn at screen row y, or -1.
+func (m model) itemAt(y int) int {
+	start, end := m.visibleRange()
+	row := m.listTop()
+	for i := start; i < end; i++ {
+		height := m.itemHeight(i)
+		if y >= row && y < row+height {
+			return i
+		}
+		row += height
+	}
+	return -1
+}
+
+func (m model) itemsInView() int {
+	if m.view == "worktrees" {
+		return m.worktreesFitting(m.scrollOffset)
+	}
+	return m.listHeight()
+}
+
+func (m model) visibleRange() (int, int) {
+	if m.view == "worktrees" {
+		return m.getViewportRangeForWorktrees(len(m.worktrees))
+	}
+	return m.getViewportRange(m.listLen())
+}
+
+// scrollBy moves the viewport by delta items, dragging the cursor along
+// when it would otherwise leave the visible range.
+func (m *model) scrollBy(delta int) {
+	maxOffset := m.listLen() - m.itemsInView()
+	if maxOffset < 0 {
+		maxOffset = 0
+	}
+
+	m.scrollOffset += delta
+	if m.scrollOffset > maxOffset {
+		m.scrollOffset = maxOffset
+	}
+	if m.scrollOffset < 0 {
+		m.scrollOffset = 0
+	}
+
+	if m.cursor < m.scrollOffset {
+		m.cursor = m.scrollOffset
+	} else if last := m.scrollOffset + m.itemsInView() - 1; m.cursor > last {
+		m.cursor = last
+	}
+	if m.cursor >= m.listLen() {
+		m.cursor = m.listLen() - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
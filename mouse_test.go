@@ -0,0 +1,114 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func click(x, y int) tea.MouseMsg {
+	return tea.MouseMsg{X: x, Y: y, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress}
+}
+
+func TestItemAtMatchesView(t *testing.T) {
+	m := initialModel()
+	m.allWorktrees = []Worktree{
+		{Path: "/src/repo", Branch: "main"},
+		{Path: "/src/repo-feature", Branch: "feature"},
+		{Path: "/src/repo-bugfix", Branch: "bugfix"},
+	}
+	m.worktrees = m.allWorktrees
+	m.statusMessage = "something happened"
+
+	lines := strings.Split(m.View(), "\n")
+	for i, wt := range m.worktrees {
+		found := false
+		for y, line := range lines {
+			if strings.Contains(line, "("+wt.Branch+")") {
+				found = true
+				if got := m.itemAt(y); got != i {
+					t.Errorf("Expected row %d (%q) to map to item %d, got %d", y, line, i, got)
+				}
+				// The path line belongs to the same item
+				if got := m.itemAt(y + 1); got != i {
+					t.Errorf("Expected path row %d to map to item %d, got %d", y+1, i, got)
+				}
+			}
+		}
+		if !found {
+			t.Fatalf("Worktree %q not rendered", wt.Branch)
+		}
+	}
+}
+
+func TestMouseClickAndDoubleClick(t *testing.T) {
+	m := initialModel()
+	m.view = "branches"
+	m.allBranches = []Branch{
+		{Name: "main", Type: "local"},
+		{Name: "feature", Type: "local"},
+	}
+	m.branches = m.allBranches
+
+	y := m.listTop() + 1
+	newModel, cmd := m.Update(click(5, y))
+	m = newModel.(model)
+	if m.cursor != 1 {
+		t.Errorf("Expected click to move cursor to 1, got %d", m.cursor)
+	}
+	if cmd != nil {
+		t.Error("Expected a single click not to trigger an action")
+	}
+
+	newModel, cmd = m.Update(click(5, y))
+	m = newModel.(model)
+	if cmd == nil || !m.creatingWorktree || m.creatingForBranch != "feature" {
+		t.Error("Expected double-click to create a worktree for the clicked branch")
+	}
+}
+
+func TestMouseTabClick(t *testing.T) {
+	m := initialModel()
+	m.cursor = 0
+
+	// The Branches tab follows the Worktrees tab on the first row
+	x := len("  Worktrees  ") + 2
+	newModel, _ := m.Update(click(x, 0))
+	m = newModel.(model)
+	if m.view != "branches" {
+		t.Errorf("Expected clicking the Branches tab to switch views, got %q", m.view)
+	}
+
+	newModel, _ = m.Update(click(1, 0))
+	m = newModel.(model)
+	if m.view != "worktrees" {
+		t.Errorf("Expected clicking the Worktrees tab to switch views, got %q", m.view)
+	}
+}
+
+func TestMouseWheel(t *testing.T) {
+	m := initialModel()
+	m.view = "branches"
+	m.viewportHeight = 3
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		m.branches = append(m.branches, Branch{Name: name, Type: "local"})
+	}
+
+	for i := 0; i < 5; i++ {
+		newModel, _ := m.Update(tea.MouseMsg{Button: tea.MouseButtonWheelDown})
+		m = newModel.(model)
+	}
+	if m.scrollOffset != 2 {
+		t.Errorf("Expected scrolling to stop at offset 2, got %d", m.scrollOffset)
+	}
+	if m.cursor != 2 {
+		t.Errorf("Expected cursor to be dragged into view at 2, got %d", m.cursor)
+	}
+
+	newModel, _ := m.Update(tea.MouseMsg{Button: tea.MouseButtonWheelUp})
+	m = newModel.(model)
+	if m.scrollOffset != 1 {
+		t.Errorf("Expected wheel up to scroll back to 1, got %d", m.scrollOffset)
+	}
+}
@@ -0,0 +1,357 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// errNothingToMove is returned when the source worktree has no changes.
+var errNothingToMove = errors.New("no uncommitted changes to move")
+
+// conflictError reports the files that kept changes from applying.
+type conflictError struct {
+	files []string
+}
+
+func (e *conflictError) Error() string {
+	if len(e.files) == 0 {
+		return "changes do not apply to the target, nothing was moved"
+	}
+	return fmt.Sprintf("changes conflict in %s, nothing was moved", strings.Join(e.files, ", "))
+}
+
+// dropStash removes the stash entry for commit. Stashes are shared by all
+// worktrees, so the entry is looked up by commit rather than position.
+func dropStash(dir, commit string) error {
+	list, err := gitIn(dir, "stash", "list", "--format=%H")
+	if err != nil {
+		return err
+	}
+	for i, entry := range strings.Split(list, "\n") {
+		if entry == commit {
+			_, err := gitIn(dir, "stash", "drop", fmt.Sprintf("stash@{%d}", i))
+			return err
+		}
+	}
+	return fmt.Errorf("stash %s not found", commit[:7])
+}
+
+// conflictedFiles lists unmerged paths in the worktree at dir.
+func conflictedFiles(dir string) []string {
+	output, err := gitIn(dir, "diff", "--name-only", "--diff-filter=U")
+	if err != nil || output == "" {
+		return nil
+	}
+	return strings.Split(output, "\n")
+}
+
+// resetWorktree throws away everything a failed apply left behind in a
+// worktree that was clean before.
+func resetWorktree(dir string) error {
+	if _, err := gitIn(dir, "reset", "--hard", "--quiet"); err != nil {
+		return err
+	}
+	_, err := gitIn(dir, "clean", "-fd", "--quiet")
+	return err
+}
+
+// applyStash applies the stash commit to the clean worktree at dir, keeping
+// staged changes staged when it can. On failure the worktree is reset and
+// the conflicting files are reported.
+func applyStash(dir, commit string) error {
+	if _, err := gitIn(dir, "stash", "apply", "--index", "--quiet", commit); err == nil {
+		return nil
+	}
+	// The index may not apply on another base, the changes alone might
+	if err := resetWorktree(dir); err != nil {
+		return err
+	}
+	if _, err := gitIn(dir, "stash", "apply", "--quiet", commit); err == nil {
+		return nil
+	}
+	conflicts := &conflictError{files: conflictedFiles(dir)}
+	if err := resetWorktree(dir); err != nil {
+		return fmt.Errorf("%v, and cleaning up the target failed: %w", conflicts, err)
+	}
+	return conflicts
+}
+
+// stashKeptError reports that the changes were moved but the stash that
+// carried them couldn't be dropped. Like a metadataError it is a warning,
+// not a failure.
+type stashKeptError struct {
+	commit string
+	err    error
+}
+
+func (e stashKeptError) Error() string {
+	return fmt.Sprintf("changes moved, but stash %s was kept (drop it with git stash drop): %v", e.commit[:7], e.err)
+}
+
+func (e stashKeptError) Unwrap() error { return e.err }
+
+// moveChanges moves the staged and unstaged changes, and untracked files
+// when includeUntracked is set, from the worktree at source to the one at
+// target, which must be clean. If they don't apply, both worktrees are left
+// as they were.
+func moveChanges(source, target string, includeUntracked bool) error {
+	if filepath.Clean(source) == filepath.Clean(target) {
+		return fmt.Errorf("source and target are the same worktree")
+	}
+
+	status := []string{"status", "--porcelain"}
+	if !includeUntracked {
+		status = append(status, "--untracked-files=no")
+	}
+	if changes, err := gitIn(source, status...); err != nil {
+		return err
+	} else if changes == "" {
+		return errNothingToMove
+	}
+
+	if isWorktreeDirty(target) {
+		return fmt.Errorf("%s has uncommitted changes of its own", filepath.Base(target))
+	}
+
+	push := []string{"stash", "push", "--quiet", "-m", "wtree: move to " + filepath.Base(target)}
+	if includeUntracked {
+		push = append(push, "--include-untracked")
+	}
+	if _, err := gitIn(source, push...); err != nil {
+		return err
+	}
+	commit, err := gitIn(source, "rev-parse", "stash@{0}")
+	if err != nil {
+		return err
+	}
+
+	if applyErr := applyStash(target, commit); applyErr != nil {
+		// Put the changes back where they came from
+		if _, err := gitIn(source, "stash", "apply", "--index", "--quiet", commit); err != nil {
+			return fmt.Errorf("%v; restoring them failed too, they are kept in stash %s: %w", applyErr, commit[:7], err)
+		}
+		if err := dropStash(source, commit); err != nil {
+			return fmt.Errorf("%w; stash %s was kept: %v", applyErr, commit[:7], err)
+		}
+		return applyErr
+	}
+	if err := dropStash(source, commit); err != nil {
+		return stashKeptError{commit: commit, err: err}
+	}
+	return nil
+}
+
+// moveChangesToNewBranch creates a worktree for a new branch off the
+// source's HEAD, so the changes apply to the code they were made against,
+// and moves them there. The worktree and branch are removed again if the
+// changes don't apply.
+func moveChangesToNewBranch(source, branchName string, includeUntracked bool, opts createOptions) (string, error) {
+	head, err := gitIn(source, "rev-parse", "--verify", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	path, err := createNewBranchWorktreeFrom(source, branchName, head, opts)
+	if err != nil && !isMetadataError(err) {
+		return "", err
+	}
+	moveErr := moveChanges(source, path, includeUntracked)
+	if moveErr != nil && !isWarning(moveErr) {
+		removeNewBranchWorktree(source, path, branchName)
+		return "", moveErr
+	}
+	return path, errors.Join(err, moveErr)
+}
+
+// removeNewBranchWorktree undoes creating a worktree for a new branch in
+// repo.
+func removeNewBranchWorktree(repo, path, branchName string) error {
+	if err := deleteWorktree(repo, Worktree{Path: path}); err != nil && !isMetadataError(err) {
+		return err
+	}
+	return exec.Command("git", "-C", repo, "branch", "-D", branchName).Run()
+}
+
+// findWorktree matches name against the path, directory name and branch of
+// each worktree.
+func findWorktree(worktrees []Worktree, name string) (Worktree, bool) {
+	for _, worktree := range worktrees {
+		if worktree.Path == name || filepath.Base(worktree.Path) == name || worktree.Branch == name {
+			return worktree, true
+		}
+	}
+	return Worktree{}, false
+}
+
+type changesMovedMsg struct {
+	path    string
+	created bool // the worktree was created for a new branch
+}
+
+func moveChangesCmd(source, target string, includeUntracked bool) tea.Cmd {
+	return func() tea.Msg {
+		err := moveChanges(source, target, includeUntracked)
+		return resultMsg(changesMovedMsg{path: target}, err)
+	}
+}
+
+func moveChangesToNewBranchCmd(source, branchName string, includeUntracked bool, opts createOptions) tea.Cmd {
+	return func() tea.Msg {
+		path, err := moveChangesToNewBranch(source, branchName, includeUntracked, opts)
+		return resultMsg(changesMovedMsg{path: path, created: true}, err)
+	}
+}
+
+func (m *model) startMove(worktree Worktree) tea.Cmd {
+	m.movingChanges = true
+	m.moveSource = worktree
+	m.moveTarget = -1
+	m.moveUntracked = false
+	m.moveInput.SetValue("")
+	return m.moveInput.Focus()
+}
+
+func (m *model) cancelMove() {
+	m.movingChanges = false
+	m.moveSource = Worktree{}
+	m.moveInput.SetValue("")
+	m.moveInput.Blur()
+}
+
+// cycleWorktree steps index to the next (delta 1) or previous (delta -1)
+// worktree other than the one at exclude, wrapping around. An index of -1
+// starts from either end.
+func (m model) cycleWorktree(exclude string, index, delta int) (int, Worktree, bool) {
+	var targets []Worktree
+	for _, worktree := range m.allWorktrees {
+		if worktree.Path != exclude {
+			targets = append(targets, worktree)
+		}
+	}
+	if len(targets) == 0 {
+		return index, Worktree{}, false
+	}
+	if index < 0 && delta < 0 {
+		index = 0
+	}
+	index = (index + delta + len(targets)) % len(targets)
+	return index, targets[index], true
+}
+
+// cycleMoveTarget fills the input with the next (delta 1) or previous
+// (delta -1) worktree other than the source.
+func (m *model) cycleMoveTarget(delta int) {
+	index, target, ok := m.cycleWorktree(m.moveSource.Path, m.moveTarget, delta)
+	if !ok {
+		return
+	}
+	m.moveTarget = index
+	m.moveInput.SetValue(filepath.Base(target.Path))
+	m.moveInput.CursorEnd()
+}
+
+// confirmMove moves the changes to the named worktree, or creates a new
+// branch worktree for them when no worktree goes by that name.
+func (m model) confirmMove() (model, tea.Cmd) {
+	name := strings.TrimSpace(m.moveInput.Value())
+	if name == "" {
+		return m, nil
+	}
+	source, untracked := m.moveSource.Path, m.moveUntracked
+
+	if target, ok := findWorktree(m.allWorktrees, name); ok {
+		m.cancelMove()
+		m.statusMessage = fmt.Sprintf("Moving changes to %s...", filepath.Base(target.Path))
+		return m, moveChangesCmd(source, target.Path, untracked)
+	}
+
+	rules, err := loadNamingRules(m.repo, m.config)
+	if err == nil {
+		err = checkNewBranch(m.repo, name, m.allBranches, rules)
+	}
+	var opts createOptions
+	if err == nil {
+		opts, err = m.worktreeOptions(m.newSparse)
+	}
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("%s %v", markers.Error, err)
+		return m, clearStatusAfterDelay()
+	}
+	m.cancelMove()
+	m.statusMessage = fmt.Sprintf("Moving changes to new branch '%s'...", name)
+	return m, moveChangesToNewBranchCmd(source, name, untracked, opts)
+}
+
+func (m model) renderMoveInput() string {
+	untracked := "without untracked files"
+	if m.moveUntracked {
+		untracked = "with untracked files"
+	}
+	return inputStyle.Render(fmt.Sprintf("Move changes of %s to: ", filepath.Base(m.moveSource.Path))) +
+		m.moveInput.View() + pathStyle.Render("  "+untracked)
+}
+
+// runMoveCommand moves the changes of the current worktree.
+func runMoveCommand(cfg Config, args []string) error {
+	flags := flag.NewFlagSet("move", flag.ContinueOnError)
+	untracked := flags.Bool("u", false, "Include untracked files")
+	newBranch := flags.Bool("b", false, "Create a new branch worktree for the changes")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: wtree move [-u] [-b] <worktree or new branch>")
+	}
+	name := flags.Arg(0)
+
+	source, err := getRepoRoot(".")
+	if err != nil {
+		return err
+	}
+
+	if *newBranch {
+		branches, err := getBranches(".")
+		if err != nil {
+			return err
+		}
+		rules, err := loadNamingRules(".", cfg)
+		if err != nil {
+			return err
+		}
+		if err := checkNewBranch(".", name, branches, rules); err != nil {
+			return err
+		}
+		opts, err := newCreateOptions(".", cfg, defaultSparse(".", cfg))
+		if err != nil {
+			return err
+		}
+		path, err := moveChangesToNewBranch(source, name, *untracked, opts)
+		if err = warnMetadata(err); err != nil {
+			return err
+		}
+		if _, err := setupWorktree(cfg, path); err != nil {
+			return err
+		}
+		fmt.Printf("Moved changes to new worktree '%s'\n", path)
+		return nil
+	}
+
+	worktrees, err := getWorktrees(".")
+	if err != nil {
+		return err
+	}
+	target, ok := findWorktree(worktrees, name)
+	if !ok {
+		return fmt.Errorf("no worktree named '%s' (use -b for a new branch)", name)
+	}
+	if err := warnMetadata(moveChanges(source, target.Path, *untracked)); err != nil {
+		return err
+	}
+	fmt.Printf("Moved changes to '%s'\n", target.Path)
+	return nil
+}
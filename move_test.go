@@ -0,0 +1,186 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestMoveChanges(t *testing.T) {
+	repo := newTestRepo(t)
+	t.Chdir(repo)
+
+	target, err := createNewBranchWorktree(".", "feature/target", createOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, filepath.Join(repo, "README"), "hello\nworld\n")
+	writeFile(t, filepath.Join(repo, "staged.txt"), "staged\n")
+	runGit(t, repo, "add", "staged.txt")
+	writeFile(t, filepath.Join(repo, "untracked.txt"), "untracked\n")
+
+	if err := moveChanges(repo, target, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if status := runGit(t, repo, "status", "--porcelain"); status != "?? untracked.txt" {
+		t.Errorf("Expected only the untracked file left behind, got %q", status)
+	}
+	if status := runGit(t, target, "status", "--porcelain"); status != "M README\nA  staged.txt" && status != " M README\nA  staged.txt" {
+		t.Errorf("Expected the changes in the target with the index kept, got %q", status)
+	}
+	if stashes := runGit(t, repo, "stash", "list"); stashes != "" {
+		t.Errorf("Expected the stash to be dropped, got %q", stashes)
+	}
+
+	if err := moveChanges(target, repo, true); err == nil || !strings.Contains(err.Error(), "uncommitted changes") {
+		t.Errorf("Expected a dirty target to be refused, got %v", err)
+	}
+	if err := moveChanges(repo, target, false); !errors.Is(err, errNothingToMove) {
+		t.Errorf("Expected nothing to move without untracked files, got %v", err)
+	}
+}
+
+func TestMoveChangesRollsBackOnConflict(t *testing.T) {
+	repo := newTestRepo(t)
+	t.Chdir(repo)
+
+	target, err := createNewBranchWorktree(".", "feature/diverged", createOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(target, "README"), "goodbye\n")
+	runGit(t, target, "commit", "-am", "diverge")
+
+	writeFile(t, filepath.Join(repo, "README"), "hello again\n")
+	writeFile(t, filepath.Join(repo, "new.txt"), "new\n")
+
+	err = moveChanges(repo, target, true)
+	var conflict *conflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("Expected a conflict error, got %v", err)
+	}
+	if len(conflict.files) != 1 || conflict.files[0] != "README" {
+		t.Errorf("Expected README to conflict, got %v", conflict.files)
+	}
+
+	if content := readFile(t, filepath.Join(repo, "README")); content != "hello again\n" {
+		t.Errorf("Expected the source changes restored, got %q", content)
+	}
+	if content := readFile(t, filepath.Join(repo, "new.txt")); content != "new\n" {
+		t.Errorf("Expected the untracked file restored, got %q", content)
+	}
+	if status := runGit(t, target, "status", "--porcelain"); status != "" {
+		t.Errorf("Expected the target left clean, got %q", status)
+	}
+	if stashes := runGit(t, repo, "stash", "list"); stashes != "" {
+		t.Errorf("Expected no stash left behind, got %q", stashes)
+	}
+}
+
+func TestMoveChangesToNewBranch(t *testing.T) {
+	repo := newTestRepo(t)
+	t.Chdir(repo)
+
+	writeFile(t, filepath.Join(repo, "fix.txt"), "fix\n")
+	path, err := moveChangesToNewBranch(repo, "fix/wrong-place", true, createOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content := readFile(t, filepath.Join(path, "fix.txt")); content != "fix\n" {
+		t.Errorf("Expected the change in the new worktree, got %q", content)
+	}
+	if isWorktreeDirty(repo) {
+		t.Error("Expected the source to be clean")
+	}
+
+	if _, err := moveChangesToNewBranch(repo, "fix/nothing", false, createOptions{}); !errors.Is(err, errNothingToMove) {
+		t.Fatalf("Expected nothing to move, got %v", err)
+	}
+	if branches := runGit(t, repo, "branch", "--list", "fix/nothing"); branches != "" {
+		t.Errorf("Expected the new branch to be removed again, got %q", branches)
+	}
+}
+
+func TestMoveChangesToNewBranchFromSourceHead(t *testing.T) {
+	repo := newTestRepo(t)
+	t.Chdir(repo)
+
+	// A local commit the changes build on, not pushed to origin
+	writeFile(t, filepath.Join(repo, "base.txt"), "base\n")
+	runGit(t, repo, "add", "base.txt")
+	runGit(t, repo, "commit", "-m", "base")
+	writeFile(t, filepath.Join(repo, "base.txt"), "base\nfix\n")
+
+	path, err := moveChangesToNewBranch(repo, "fix/on-head", false, createOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content := readFile(t, filepath.Join(path, "base.txt")); content != "base\nfix\n" {
+		t.Errorf("Expected the change in the new worktree, got %q", content)
+	}
+	if head, branch := runGit(t, repo, "rev-parse", "HEAD"), runGit(t, path, "rev-parse", "HEAD"); branch != head {
+		t.Errorf("Expected the new branch at the source HEAD %s, got %s", head, branch)
+	}
+}
+
+func TestStashKeptIsAWarning(t *testing.T) {
+	err := stashKeptError{commit: "0123456789abcdef", err: errors.New("locked")}
+	msg, ok := resultMsg(changesMovedMsg{path: "/tmp/target"}, err).(warningMsg)
+	if !ok {
+		t.Fatalf("Expected a warning, got %#v", msg)
+	}
+	if !strings.Contains(msg.warning, "stash 0123456") {
+		t.Errorf("Expected the warning to name the stash, got %q", msg.warning)
+	}
+	if warnMetadata(err) != nil {
+		t.Error("Expected the command line to treat it as a warning")
+	}
+}
+
+func TestMoveChangesToSparseNewBranch(t *testing.T) {
+	repo := newTestRepo(t)
+	t.Chdir(repo)
+
+	for _, dir := range []string{"apps/web", "apps/api"} {
+		if err := os.MkdirAll(filepath.Join(repo, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, filepath.Join(repo, dir, "main.go"), "package main\n")
+	}
+	runGit(t, repo, "add", ".")
+	runGit(t, repo, "commit", "-m", "apps")
+	runGit(t, repo, "push", "origin", "main")
+
+	writeFile(t, filepath.Join(repo, "apps/web/main.go"), "package web\n")
+	web := SparseProfile{Name: "web", Dirs: []string{"apps/web"}}
+	path, err := moveChangesToNewBranch(repo, "fix/web", false, createOptions{sparse: web})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content := readFile(t, filepath.Join(path, "apps/web/main.go")); content != "package web\n" {
+		t.Errorf("Expected the change in the new worktree, got %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(path, "apps/api")); err == nil {
+		t.Error("Expected the new worktree to use the sparse profile")
+	}
+}
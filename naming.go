@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// NamingRules constrain new branch names in a repository. Prefixes may
+// contain "{user}", which expands to the slugified `git config user.name`.
+type NamingRules struct {
+	Prefixes  []string `json:"prefixes"`   // allowed prefixes, offered by the prefix picker
+	Pattern   string   `json:"pattern"`    // regular expression the whole name must match
+	MaxLength int      `json:"max_length"` // 0 means unlimited
+}
+
+// validate reports configuration errors, such as a pattern that doesn't
+// compile, up front rather than on the first keystroke.
+func (r NamingRules) validate() error {
+	if r.Pattern == "" {
+		return nil
+	}
+	if _, err := regexp.Compile(r.Pattern); err != nil {
+		return fmt.Errorf("invalid branch naming pattern %q: %w", r.Pattern, err)
+	}
+	return nil
+}
+
+// check enforces the rules on name. Prefixes are expected to be expanded.
+func (r NamingRules) check(name string) error {
+	if len(r.Prefixes) > 0 {
+		allowed := false
+		for _, prefix := range r.Prefixes {
+			if strings.HasPrefix(name, prefix) && len(name) > len(prefix) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("must start with one of %s", strings.Join(r.Prefixes, ", "))
+		}
+	}
+	if r.MaxLength > 0 && len(name) > r.MaxLength {
+		return fmt.Errorf("longer than %d characters", r.MaxLength)
+	}
+	if r.Pattern != "" {
+		pattern, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return err
+		}
+		if !pattern.MatchString(name) {
+			return fmt.Errorf("must match %s", r.Pattern)
+		}
+	}
+	return nil
+}
+
+// expand replaces "{user}" in the prefixes with user. Without a user the
+// prefixes that need one are left out, and when that leaves none it fails
+// rather than allow any name.
+func (r NamingRules) expand(user string) (NamingRules, error) {
+	expanded := r
+	expanded.Prefixes = nil
+	for _, prefix := range r.Prefixes {
+		if user == "" && strings.Contains(prefix, "{user}") {
+			continue
+		}
+		expanded.Prefixes = append(expanded.Prefixes, strings.ReplaceAll(prefix, "{user}", user))
+	}
+	if len(r.Prefixes) > 0 && len(expanded.Prefixes) == 0 {
+		return expanded, fmt.Errorf("the branch prefixes need {user}; set git config user.name")
+	}
+	return expanded, nil
+}
+
+// rulesFor picks the rules for the repository at repoRoot.
+func rulesFor(naming map[string]NamingRules, repoRoot string) NamingRules {
+	return repoEntry(naming, repoRoot)
+}
+
+// repoEntry picks the entry of a per-repository config map for the
+// repository at repoRoot: the one keyed by its path, then by its directory
+// name, then the "*" entry.
+func repoEntry[T any](entries map[string]T, repoRoot string) T {
+	for key, entry := range entries {
+		if key != "*" && filepath.Clean(expandHome(key)) == repoRoot {
+			return entry
+		}
+	}
+	if entry, ok := entries[filepath.Base(repoRoot)]; ok {
+		return entry
+	}
+	return entries["*"]
+}
+
+// loadNamingRules resolves the naming rules of repo with prefixes expanded
+// for the configured git user.
+func loadNamingRules(repo string, cfg Config) (NamingRules, error) {
+	if len(cfg.Naming) == 0 {
+		return NamingRules{}, nil
+	}
+	repoRoot, err := getRepoRoot(repo)
+	if err != nil {
+		return NamingRules{}, err
+	}
+	return rulesFor(cfg.Naming, repoRoot).expand(gitUserSlug(repo))
+}
+
+func gitUserSlug(repo string) string {
+	output, err := exec.Command("git", "-C", repo, "config", "user.name").Output()
+	if err != nil {
+		return ""
+	}
+	return slugify(strings.TrimSpace(string(output)))
+}
+
+// currentPrefix is the prefix selected in the new branch prefix picker.
+func (m model) currentPrefix() string {
+	if len(m.newBranchRules.Prefixes) == 0 {
+		return ""
+	}
+	return m.newBranchRules.Prefixes[m.newBranchPrefix]
+}
+
+// cyclePrefix selects the next (delta 1) or previous (delta -1) prefix and
+// swaps it into the input, keeping whatever was typed after it.
+func (m *model) cyclePrefix(delta int) {
+	count := len(m.newBranchRules.Prefixes)
+	if count == 0 {
+		return
+	}
+	rest := strings.TrimPrefix(m.newBranchInput.Value(), m.currentPrefix())
+	m.newBranchPrefix = (m.newBranchPrefix + delta + count) % count
+	m.newBranchInput.SetValue(m.currentPrefix() + rest)
+	m.newBranchInput.CursorEnd()
+	m.updateNewBranchError()
+}
+
+// renderPrefixPicker lists the allowed prefixes with the selected one
+// highlighted.
+func (m model) renderPrefixPicker() string {
+	parts := make([]string, len(m.newBranchRules.Prefixes))
+	for i, prefix := range m.newBranchRules.Prefixes {
+		if i == m.newBranchPrefix {
+			parts[i] = selectedTextStyle.Render(prefix)
+		} else {
+			parts[i] = pathStyle.Render(prefix)
+		}
+	}
+	return inputStyle.Render("Prefix: ") + strings.Join(parts, " ")
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestNamingRulesCheck(t *testing.T) {
+	rules, err := NamingRules{
+		Prefixes:  []string{"feature/", "fix/", "user/{user}/"},
+		Pattern:   `^[a-z0-9/-]+$`,
+		MaxLength: 20,
+	}.expand("jane-doe")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"feature/login", true},
+		{"user/jane-doe/spike", true},
+		{"user/{user}/spike", false},
+		{"login", false},
+		{"feature/", false},
+		{"feature/UPPER", false},
+		{"feature/this-is-far-too-long", false},
+	}
+	for _, test := range tests {
+		if err := rules.check(test.name); (err == nil) != test.valid {
+			t.Errorf("check(%q) = %v, expected valid=%v", test.name, err, test.valid)
+		}
+	}
+
+	if err := (NamingRules{}).check("anything"); err != nil {
+		t.Errorf("Expected empty rules to allow anything, got %v", err)
+	}
+	if err := (NamingRules{Pattern: "("}).validate(); err == nil {
+		t.Error("Expected an invalid pattern to be reported")
+	}
+}
+
+func TestNamingRulesWithoutUser(t *testing.T) {
+	// Without a user name "{user}/" would expand to "/"
+	rules, err := NamingRules{Prefixes: []string{"{user}/", "feature/"}}.expand("")
+	if err != nil || strings.Join(rules.Prefixes, ",") != "feature/" {
+		t.Errorf("Expected only the prefixes without {user}, got %q (%v)", rules.Prefixes, err)
+	}
+	if _, err := (NamingRules{Prefixes: []string{"{user}/"}}).expand(""); err == nil || !strings.Contains(err.Error(), "user.name") {
+		t.Errorf("Expected a hint to set user.name, got %v", err)
+	}
+
+	m := initialModel()
+	m.view = "branches"
+	m.config.Naming = map[string]NamingRules{"*": {Prefixes: []string{"{user}/"}}}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_PARAMETERS", "'user.name='")
+	m.startNewBranch()
+	if m.creatingBranch || !strings.Contains(m.statusMessage, "user.name") {
+		t.Errorf("Expected the new branch input to stay closed, got %q", m.statusMessage)
+	}
+}
+
+func TestRulesFor(t *testing.T) {
+	naming := map[string]NamingRules{
+		"*":        {MaxLength: 1},
+		"api":      {MaxLength: 2},
+		"/src/web": {MaxLength: 3},
+	}
+	tests := map[string]int{
+		"/src/web":   3,
+		"/other/api": 2,
+		"/src/cli":   1,
+	}
+	for root, expected := range tests {
+		if rules := rulesFor(naming, root); rules.MaxLength != expected {
+			t.Errorf("rulesFor(%q) picked max_length %d, expected %d", root, rules.MaxLength, expected)
+		}
+	}
+}
+
+func TestPrefixPicker(t *testing.T) {
+	m := initialModel()
+	m.view = "branches"
+	m.config.Naming = map[string]NamingRules{"*": {Prefixes: []string{"user/{user}/", "feature/"}}}
+	t.Setenv("GIT_CONFIG_PARAMETERS", "'user.name=Jane Doe'")
+	m.startNewBranch()
+
+	if m.newBranchInput.Value() != "user/jane-doe/" {
+		t.Fatalf("Expected the input prefilled with the user prefix, got %q", m.newBranchInput.Value())
+	}
+
+	m.newBranchInput.SetValue("user/jane-doe/login")
+	m.cyclePrefix(1)
+	if m.newBranchInput.Value() != "feature/login" {
+		t.Errorf("Expected the prefix swapped, got %q", m.newBranchInput.Value())
+	}
+	if !strings.Contains(m.View(), "Prefix: ") {
+		t.Error("Expected the prefix picker in the view")
+	}
+
+	m.newBranchInput.SetValue("login")
+	m.updateNewBranchError()
+	if !strings.Contains(m.newBranchError, "must start with") {
+		t.Errorf("Expected a prefix error, got %q", m.newBranchError)
+	}
+	if _, cmd := m.confirmNewBranch(); cmd != nil {
+		t.Error("Expected a name breaking the naming rules not to be created")
+	}
+
+	if name := m.withPrefix("ABC-1-title"); name != "feature/ABC-1-title" {
+		t.Errorf("Expected the selected prefix on a proposal, got %q", name)
+	}
+	if name := m.withPrefix("user/jane-doe/ABC-1"); name != "user/jane-doe/ABC-1" {
+		t.Errorf("Expected an already prefixed proposal unchanged, got %q", name)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// noteFirstLine is the part of a note shown under the worktree path.
+func noteFirstLine(note string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(note), "\n")
+	return strings.TrimSpace(line)
+}
+
+// parseLabels splits a comma separated list, dropping blanks and
+// duplicates.
+func parseLabels(value string) []string {
+	var labels []string
+	seen := make(map[string]bool)
+	for _, label := range strings.Split(value, ",") {
+		label = strings.TrimSpace(label)
+		if label != "" && !seen[label] {
+			seen[label] = true
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
+
+func hasLabel(labels []string, label string) bool {
+	for _, l := range labels {
+		if strings.EqualFold(l, label) {
+			return true
+		}
+	}
+	return false
+}
+
+func renderLabels(labels []string) string {
+	parts := make([]string, len(labels))
+	for i, label := range labels {
+		parts[i] = labelStyle.Render("#" + label)
+	}
+	return strings.Join(parts, " ")
+}
+
+func newNoteInput() textarea.Model {
+	input := textarea.New()
+	input.Placeholder = "Why does this worktree exist?"
+	input.ShowLineNumbers = false
+	input.CharLimit = 2000
+	input.SetWidth(60)
+	input.SetHeight(5)
+	return input
+}
+
+func saveNoteCmd(repo, path, note string) tea.Cmd {
+	return func() tea.Msg {
+		err := editWorktreeMeta(repo, path, func(meta *WorktreeMeta) {
+			meta.Note = strings.TrimSpace(note)
+		})
+		if err != nil {
+			return err
+		}
+		return metadataSavedMsg{path: path}
+	}
+}
+
+func (m *model) startNoteEditor(worktree Worktree) tea.Cmd {
+	m.editingNote = true
+	m.notePath = worktree.Path
+	m.noteInput = newNoteInput()
+	m.noteInput.SetValue(worktree.Meta.Note)
+	return m.noteInput.Focus()
+}
+
+func (m *model) closeNoteEditor() {
+	m.editingNote = false
+	m.notePath = ""
+	m.noteInput.Blur()
+}
+
+func (m model) saveNote() (model, tea.Cmd) {
+	path, note := m.notePath, m.noteInput.Value()
+	m.closeNoteEditor()
+	return m, saveNoteCmd(m.repo, path, note)
+}
+
+func (m model) renderNoteEditor() string {
+	return fmt.Sprintf("%s\n%s\n", inputStyle.Render("Note for "+filepath.Base(m.notePath)), m.noteInput.View())
+}
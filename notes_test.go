@@ -0,0 +1,78 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestNoteFirstLine(t *testing.T) {
+	tests := map[string]string{
+		"":                         "",
+		"single line":              "single line",
+		"\n  first  \nsecond line": "first",
+	}
+	for note, expected := range tests {
+		if line := noteFirstLine(note); line != expected {
+			t.Errorf("noteFirstLine(%q) = %q, expected %q", note, line, expected)
+		}
+	}
+}
+
+func TestParseLabels(t *testing.T) {
+	if labels := parseLabels(" wip,, review , wip "); !reflect.DeepEqual(labels, []string{"wip", "review"}) {
+		t.Errorf("Expected [wip review], got %v", labels)
+	}
+	if labels := parseLabels(" "); labels != nil {
+		t.Errorf("Expected no labels, got %v", labels)
+	}
+}
+
+func TestWorktreesWithNotesScroll(t *testing.T) {
+	m := initialModel()
+	m.viewportHeight = 6
+	for _, note := range []string{"", "why", "", "", "why"} {
+		m.worktrees = append(m.worktrees, Worktree{Path: "/src/repo", Meta: WorktreeMeta{Note: note}})
+	}
+
+	// 2 + 3 lines fit, the third worktree doesn't
+	if start, end := m.getViewportRangeForWorktrees(len(m.worktrees)); start != 0 || end != 2 {
+		t.Errorf("Expected worktrees 0-2 in view, got %d-%d", start, end)
+	}
+
+	m.cursor = 4
+	m.adjustScrollOffset()
+	start, end := m.getViewportRangeForWorktrees(len(m.worktrees))
+	if end != 5 || start != 3 {
+		t.Errorf("Expected worktrees 3-5 in view, got %d-%d", start, end)
+	}
+}
+
+func TestNoteEditorKeepsEnter(t *testing.T) {
+	m := initialModel()
+	m.view = "worktrees"
+	m.worktrees = []Worktree{{Path: "/src/repo-fix"}}
+	m.startNoteEditor(m.worktrees[0])
+
+	for _, msg := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune("why")},
+		{Type: tea.KeyEnter},
+		{Type: tea.KeyRunes, Runes: []rune("because")},
+	} {
+		newModel, _ := m.Update(msg)
+		m = newModel.(model)
+	}
+	if !m.editingNote {
+		t.Fatal("Expected enter to stay in the note editor")
+	}
+	if value := m.noteInput.Value(); value != "why\nbecause" {
+		t.Errorf("Expected a two line note, got %q", value)
+	}
+
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	m = newModel.(model)
+	if m.editingNote || cmd == nil {
+		t.Error("Expected ctrl+s to close the editor and save")
+	}
+}
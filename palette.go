@@ -0,0 +1,341 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sahilm/fuzzy"
+)
+
+// paletteAction is one entry of the command palette. binding is the key
+// that triggers the same action outside the palette, if any.
+type paletteAction struct {
+	title   string
+	binding key.Binding
+	run     func(m model) (model, tea.Cmd)
+}
+
+// availableActions lists every action that applies to the current view
+// and selection.
+func (m model) availableActions() []paletteAction {
+	var actions []paletteAction
+	add := func(title string, binding key.Binding, run func(m model) (model, tea.Cmd)) {
+		actions = append(actions, paletteAction{title: title, binding: binding, run: run})
+	}
+	none := key.Binding{}
+
+	if worktree, ok := m.selectedWorktree(); ok {
+		name := filepath.Base(worktree.Path)
+		add("Open "+name, m.keys.Select, func(m model) (model, tea.Cmd) {
+			return m.activateSelection()
+		})
+		for _, label := range sortedKeys(m.config.OpenWith) {
+			command := m.config.OpenWith[label]
+			add(fmt.Sprintf("Open %s with %s", name, label), none, func(m model) (model, tea.Cmd) {
+				return m, openWorktreeWithCmd(m.repo, worktree, command)
+			})
+		}
+		add("Pull "+name, m.keys.Pull, func(m model) (model, tea.Cmd) {
+			return m.startPull(worktree)
+		})
+		pushTitle := "Push " + name
+		if worktree.Upstream == "" {
+			pushTitle += " and set upstream"
+		}
+		add(pushTitle, m.keys.Push, func(m model) (model, tea.Cmd) {
+			return m.startPush(worktree)
+		})
+		add("Edit details of "+name, m.keys.Edit, func(m model) (model, tea.Cmd) {
+			return m, m.startMetaForm(worktree)
+		})
+		add("Edit note of "+name, m.keys.Note, func(m model) (model, tea.Cmd) {
+			return m, m.startNoteEditor(worktree)
+		})
+		add("Move changes of "+name+" to another worktree", m.keys.MoveChanges, func(m model) (model, tea.Cmd) {
+			return m, m.startMove(worktree)
+		})
+		if worktree.Dirty {
+			add("Move changes of "+name+" to a new branch", m.keys.Promote, func(m model) (model, tea.Cmd) {
+				return m, m.startPromote(worktree)
+			})
+		}
+		if len(m.config.Sparse) > 0 {
+			add("Change sparse profile of "+name, m.keys.Sparse, func(m model) (model, tea.Cmd) {
+				return m, m.startSparsePicker(worktree.Path)
+			})
+		}
+		if pointers, _ := m.lfsPointers.get(worktree.Path); pointers > 0 {
+			add("Pull LFS files in "+name, none, func(m model) (model, tea.Cmd) {
+				m.statusMessage = fmt.Sprintf("Pulling LFS files in %s...", name)
+				return m, lfsPullCmd(worktree.Path, nil)
+			})
+		}
+		markTitle := "Mark " + name + " for cleanup"
+		if m.marked[worktree.Path] {
+			markTitle = "Unmark " + name
+		}
+		add(markTitle, m.keys.Mark, func(m model) (model, tea.Cmd) {
+			m.toggleMark(worktree.Path)
+			return m, nil
+		})
+		cleanTitle := "Remove ignored files in " + name
+		if targets := m.cleanTargets(); len(targets) > 1 {
+			cleanTitle = fmt.Sprintf("Remove ignored files in %d marked worktrees", len(targets))
+		} else if len(targets) == 1 {
+			cleanTitle = "Remove ignored files in " + filepath.Base(targets[0])
+		}
+		add(cleanTitle, m.keys.Clean, func(m model) (model, tea.Cmd) {
+			return m, m.startClean()
+		})
+		pinTitle := "Pin " + name
+		if worktree.Meta.Pinned {
+			pinTitle = "Unpin " + name
+		}
+		add(pinTitle, m.keys.Pin, func(m model) (model, tea.Cmd) {
+			return m, togglePinCmd(m.repo, worktree)
+		})
+		if !m.deletingWorktree {
+			add("Delete worktree "+name, m.keys.Delete, func(m model) (model, tea.Cmd) {
+				return m, deleteWorktreeCmd(worktree)
+			})
+		}
+		for _, label := range sortedKeys(m.config.Hooks) {
+			command := m.config.Hooks[label]
+			add(fmt.Sprintf("Run hook '%s' in %s", label, name), none, func(m model) (model, tea.Cmd) {
+				m.statusMessage = fmt.Sprintf("Running hook '%s'...", label)
+				return m, runHookCmd(worktree, label, command)
+			})
+		}
+	}
+
+	if branch, ok := m.selectedBranch(); ok {
+		title := "Create worktree for " + branch.Name
+		if branch.Type == "tag" {
+			title = "Create detached worktree at tag " + branch.Name
+		}
+		add(title, m.keys.Select, func(m model) (model, tea.Cmd) {
+			return m.activateSelection()
+		})
+	}
+
+	if stash, ok := m.selectedStash(); ok {
+		add("Apply "+stash.Ref+" to a worktree", m.keys.Apply, func(m model) (model, tea.Cmd) {
+			return m, m.startApplyStash(stash, false)
+		})
+		add("Pop "+stash.Ref+" into a worktree", m.keys.Pop, func(m model) (model, tea.Cmd) {
+			return m, m.startApplyStash(stash, true)
+		})
+		add("Drop "+stash.Ref, m.keys.Delete, func(m model) (model, tea.Cmd) {
+			m.startDropStash(stash)
+			return m, nil
+		})
+	}
+
+	add("New branch and worktree", m.keys.NewBranch, func(m model) (model, tea.Cmd) {
+		if m.view != "branches" {
+			m.switchView("branches")
+		}
+		return m, m.startNewBranch()
+	})
+	if len(m.config.Sparse) > 0 {
+		add("Choose sparse profile for new worktrees", none, func(m model) (model, tea.Cmd) {
+			return m, m.startSparsePicker("")
+		})
+	}
+	add("Filter "+m.view, m.keys.Filter, func(m model) (model, tea.Cmd) {
+		return m, m.startFilter()
+	})
+	add("Create worktree from pull request", m.keys.PullRequest, func(m model) (model, tea.Cmd) {
+		return m, m.startPRInput()
+	})
+	add("Check out a commit in a detached worktree", m.keys.CheckoutCommit, func(m model) (model, tea.Cmd) {
+		return m, m.startCommitInput()
+	})
+	add("Create scratch worktree", m.keys.Scratch, func(m model) (model, tea.Cmd) {
+		return m.startScratch()
+	})
+	add("Remove expired scratch worktrees", none, func(m model) (model, tea.Cmd) {
+		m.statusMessage = "Removing expired worktrees..."
+		return m, collectExpiredWorktreesCmd(m.repo)
+	})
+	if m.view == "worktrees" {
+		add("Measure disk usage again", none, func(m model) (model, tea.Cmd) {
+			return m.remeasureDiskUsage()
+		})
+	}
+	add("Fetch all remotes", m.keys.Fetch, func(m model) (model, tea.Cmd) {
+		return m.startFetch()
+	})
+	add("Prune stale worktrees", none, func(m model) (model, tea.Cmd) {
+		return m, pruneWorktreesCmd(m.repo)
+	})
+
+	for _, view := range views {
+		if view == m.view {
+			continue
+		}
+		binding := none
+		if view == nextView(m.view) {
+			binding = m.keys.SwitchView
+		}
+		add("Switch to "+view, binding, func(m model) (model, tea.Cmd) {
+			m.switchView(view)
+			return m, nil
+		})
+	}
+
+	for _, repo := range m.config.Repos {
+		add("Switch repo to "+repo, none, func(m model) (model, tea.Cmd) {
+			return m, switchRepoCmd(repo)
+		})
+	}
+
+	add("Show key bindings", m.keys.Help, func(m model) (model, tea.Cmd) {
+		m.showHelp = true
+		return m, nil
+	})
+	add("Quit", m.keys.Quit, func(m model) (model, tea.Cmd) {
+		return m, tea.Quit
+	})
+
+	return actions
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (m model) selectedWorktree() (Worktree, bool) {
+	if m.view != "worktrees" || m.cursor >= len(m.worktrees) {
+		return Worktree{}, false
+	}
+	return m.worktrees[m.cursor], true
+}
+
+// selectedBranch returns the branch, or in the Tags view the tag, under
+// the cursor.
+func (m model) selectedBranch() (Branch, bool) {
+	if m.view == "tags" && m.cursor < len(m.tags) {
+		return m.tags[m.cursor], true
+	}
+	if m.view != "branches" || m.cursor >= len(m.branches) {
+		return Branch{}, false
+	}
+	return m.branches[m.cursor], true
+}
+
+func (m model) selectedStash() (Stash, bool) {
+	if m.view != "stashes" || m.cursor >= len(m.stashes) {
+		return Stash{}, false
+	}
+	return m.stashes[m.cursor], true
+}
+
+func (m *model) openPalette() tea.Cmd {
+	m.paletteOpen = true
+	m.paletteInput.SetValue("")
+	m.paletteCursor = 0
+	m.filterPalette()
+	return m.paletteInput.Focus()
+}
+
+func (m *model) closePalette() {
+	m.paletteOpen = false
+	m.paletteInput.SetValue("")
+	m.paletteInput.Blur()
+	m.paletteActions = nil
+}
+
+func (m *model) filterPalette() {
+	actions := m.availableActions()
+	query := m.paletteInput.Value()
+	if query == "" {
+		m.paletteActions = actions
+	} else {
+		titles := make([]string, len(actions))
+		for i, action := range actions {
+			titles[i] = action.title
+		}
+		matches := fuzzy.Find(query, titles)
+		m.paletteActions = make([]paletteAction, 0, len(matches))
+		for _, match := range matches {
+			m.paletteActions = append(m.paletteActions, actions[match.Index])
+		}
+	}
+
+	if m.paletteCursor >= len(m.paletteActions) {
+		m.paletteCursor = 0
+	}
+}
+
+func (m model) updatePalette(msg tea.KeyMsg, cmds []tea.Cmd) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Cancel):
+		m.closePalette()
+		return m, nil
+	case key.Matches(msg, m.keys.Confirm):
+		if len(m.paletteActions) == 0 {
+			return m, nil
+		}
+		action := m.paletteActions[m.paletteCursor]
+		m.closePalette()
+		return action.run(m)
+	case key.Matches(msg, m.keys.InputUp):
+		if m.paletteCursor > 0 {
+			m.paletteCursor--
+		}
+	case key.Matches(msg, m.keys.InputDown):
+		if m.paletteCursor < len(m.paletteActions)-1 {
+			m.paletteCursor++
+		}
+	default:
+		m.filterPalette()
+	}
+	return m, tea.Batch(cmds...)
+}
+
+func (m model) renderPalette() string {
+	var content strings.Builder
+
+	content.WriteString(m.renderHeader())
+	content.WriteString("\n\n")
+	content.WriteString(inputStyle.Render("> "))
+	content.WriteString(m.paletteInput.View())
+	content.WriteString("\n")
+
+	if len(m.paletteActions) == 0 {
+		content.WriteString(errorStyle.Render("No matching commands."))
+		content.WriteString("\n")
+	}
+
+	// Keep the cursor visible when there are more actions than rows
+	start := 0
+	if m.paletteCursor >= m.viewportHeight {
+		start = m.paletteCursor - m.viewportHeight + 1
+	}
+	for i := start; i < len(m.paletteActions) && i < start+m.viewportHeight; i++ {
+		action := m.paletteActions[i]
+		line := action.title
+		if keys := action.binding.Keys(); len(keys) > 0 && action.binding.Enabled() {
+			line += "  " + pathStyle.Render(withHelp(action.binding, "").Help().Key)
+		}
+		if i == m.paletteCursor {
+			content.WriteString(selectedItemStyle.Render(markers.Cursor + " " + line))
+		} else {
+			content.WriteString(normalItemStyle.Render("  " + line))
+		}
+		content.WriteString("\n")
+	}
+
+	content.WriteString(helpStyle.Render(m.help.ShortHelpView(m.helpKeys().ShortHelp())))
+	return content.String()
+}
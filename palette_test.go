@@ -0,0 +1,94 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func actionTitles(actions []paletteAction) []string {
+	var titles []string
+	for _, action := range actions {
+		titles = append(titles, action.title)
+	}
+	return titles
+}
+
+func hasTitle(actions []paletteAction, prefix string) bool {
+	for _, action := range actions {
+		if strings.HasPrefix(action.title, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPaletteActionsAreContextAware(t *testing.T) {
+	m := initialModel()
+	m.config.Hooks = map[string]string{"install": "npm ci"}
+	m.worktrees = []Worktree{{Path: "/src/repo-feature", Branch: "feature"}}
+	m.branches = []Branch{{Name: "origin/fix", Type: "remote"}}
+
+	actions := m.availableActions()
+	if !hasTitle(actions, "Delete worktree repo-feature") {
+		t.Errorf("Expected delete action for the selected worktree, got %v", actionTitles(actions))
+	}
+	if !hasTitle(actions, "Run hook 'install' in repo-feature") {
+		t.Errorf("Expected configured hook action, got %v", actionTitles(actions))
+	}
+	if hasTitle(actions, "Create worktree for") {
+		t.Error("Expected no branch actions in the worktrees view")
+	}
+
+	m.view = "branches"
+	actions = m.availableActions()
+	if !hasTitle(actions, "Create worktree for origin/fix") {
+		t.Errorf("Expected create action for the selected branch, got %v", actionTitles(actions))
+	}
+	if hasTitle(actions, "Delete worktree") {
+		t.Error("Expected no worktree actions in the branches view")
+	}
+}
+
+func TestPaletteRunsAction(t *testing.T) {
+	m := initialModel()
+	m.worktrees = []Worktree{{Path: "/src/repo", Branch: "main"}}
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	m = newModel.(model)
+	if !m.paletteOpen {
+		t.Fatal("Expected ctrl+p to open the palette")
+	}
+
+	for _, r := range "prune" {
+		newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = newModel.(model)
+	}
+	if len(m.paletteActions) == 0 || m.paletteActions[0].title != "Prune stale worktrees" {
+		t.Fatalf("Expected prune to be the top match, got %v", actionTitles(m.paletteActions))
+	}
+	if !strings.Contains(m.View(), "Prune stale worktrees") {
+		t.Error("Expected the palette view to list the matching action")
+	}
+
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(model)
+	if m.paletteOpen {
+		t.Error("Expected the palette to close after running an action")
+	}
+	if cmd == nil {
+		t.Error("Expected the prune action to return a command")
+	}
+}
+
+func TestPaletteShowsKeyBinding(t *testing.T) {
+	m := initialModel()
+	m.worktrees = []Worktree{{Path: "/src/repo", Branch: "main"}}
+	m.openPalette()
+
+	view := m.View()
+	if !strings.Contains(view, "Delete worktree repo  d") {
+		t.Errorf("Expected the delete action to show its key, got:\n%s", view)
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// PullRequestConfig controls how pull/merge request worktrees are created.
+type PullRequestConfig struct {
+	Remote string `json:"remote"` // defaults to "origin"
+	Forge  string `json:"forge"`  // "github", "gitlab", or empty to try both
+	Branch string `json:"branch"` // local branch template, defaults to "pr-{number}"
+}
+
+func (c PullRequestConfig) remote() string {
+	if c.Remote == "" {
+		return "origin"
+	}
+	return c.Remote
+}
+
+func (c PullRequestConfig) branchName(number int) string {
+	template := c.Branch
+	if template == "" {
+		template = "pr-{number}"
+	}
+	return strings.ReplaceAll(template, "{number}", strconv.Itoa(number))
+}
+
+// refs returns the remote refs a pull request head may live under, in the
+// order they should be tried.
+func (c PullRequestConfig) refs(number int) ([]string, error) {
+	github := fmt.Sprintf("refs/pull/%d/head", number)
+	gitlab := fmt.Sprintf("refs/merge-requests/%d/head", number)
+
+	switch c.Forge {
+	case "github":
+		return []string{github}, nil
+	case "gitlab":
+		return []string{gitlab}, nil
+	case "":
+		return []string{github, gitlab}, nil
+	}
+	return nil, fmt.Errorf("unknown forge %q (expected github or gitlab)", c.Forge)
+}
+
+func parsePRNumber(s string) (int, error) {
+	number, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(s), "#"))
+	if err != nil || number <= 0 {
+		return 0, fmt.Errorf("invalid pull request number %q", s)
+	}
+	return number, nil
+}
+
+// createPRWorktree fetches the head of pull request number into a local
+// branch and adds a worktree for it. Re-running it for the same PR
+// fast-forwards the branch, so an updated PR can be fetched again once its
+// old worktree has been removed. A branch with local commits that the PR
+// doesn't contain is left alone.
+func createPRWorktree(repo string, cfg PullRequestConfig, number int, opts createOptions) (string, string, error) {
+	branch := cfg.branchName(number)
+
+	worktrees, err := getWorktrees(repo)
+	if err != nil {
+		return "", "", err
+	}
+	for _, wt := range worktrees {
+		if wt.Branch == branch {
+			return "", "", fmt.Errorf("PR #%d is already checked out at %s", number, wt.Path)
+		}
+	}
+
+	refs, err := cfg.refs(number)
+	if err != nil {
+		return "", "", err
+	}
+
+	var fetchErr error
+	for _, ref := range refs {
+		cmd := exec.Command("git", "-C", repo, "fetch", cfg.remote(), ref)
+		output, err := cmd.CombinedOutput()
+		if err == nil {
+			fetchErr = nil
+			break
+		}
+		fetchErr = fmt.Errorf("could not fetch PR #%d from %s: %s", number, cfg.remote(), strings.TrimSpace(string(output)))
+	}
+	if fetchErr != nil {
+		return "", "", fetchErr
+	}
+	head, err := gitIn(repo, "rev-parse", "--verify", "FETCH_HEAD^{commit}")
+	if err != nil {
+		return "", "", err
+	}
+
+	// Only move an existing branch forward, so local commits aren't lost
+	if _, err := gitIn(repo, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch); err == nil {
+		if _, err := gitIn(repo, "merge-base", "--is-ancestor", "refs/heads/"+branch, head); err != nil {
+			return "", "", fmt.Errorf("branch '%s' has commits that PR #%d doesn't contain; rename or delete it first", branch, number)
+		}
+	}
+	if _, err := gitIn(repo, "branch", "--force", branch, head); err != nil {
+		return "", "", err
+	}
+
+	path, err := createWorktree(repo, Branch{Name: branch, Type: "local"}, opts)
+	if err != nil && !isMetadataError(err) {
+		return "", "", err
+	}
+	return branch, path, err
+}
+
+func createPRWorktreeCmd(repo string, cfg PullRequestConfig, number int, opts createOptions) tea.Cmd {
+	return func() tea.Msg {
+		branch, path, err := createPRWorktree(repo, cfg, number, opts)
+		return resultMsg(worktreeCreatedMsg{branch: branch, path: path}, err)
+	}
+}
+
+func (m *model) startPRInput() tea.Cmd {
+	m.enteringPR = true
+	m.prInput.SetValue("")
+	return m.prInput.Focus()
+}
+
+func (m *model) cancelPRInput() {
+	m.enteringPR = false
+	m.prInput.SetValue("")
+	m.prInput.Blur()
+}
+
+func (m model) confirmPRInput() (model, tea.Cmd) {
+	number, err := parsePRNumber(m.prInput.Value())
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("%s %v", markers.Error, err)
+		return m, clearStatusAfterDelay()
+	}
+	opts, err := m.worktreeOptions(m.newSparse)
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("%s %v", markers.Error, err)
+		return m, clearStatusAfterDelay()
+	}
+	m.cancelPRInput()
+	m.creatingWorktree = true
+	m.creatingForBranch = m.config.PullRequests.branchName(number)
+	m.statusMessage = fmt.Sprintf("Fetching PR #%d and creating worktree...", number)
+	return m, createPRWorktreeCmd(m.repo, m.config.PullRequests, number, opts)
+}
+
+func runPRCommand(cfg Config, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: wtree pr <number>")
+	}
+	number, err := parsePRNumber(args[0])
+	if err != nil {
+		return err
+	}
+	opts, err := newCreateOptions(".", cfg, defaultSparse(".", cfg))
+	if err != nil {
+		return err
+	}
+	branch, path, err := createPRWorktree(".", cfg.PullRequests, number, opts)
+	if err = warnMetadata(err); err != nil {
+		return err
+	}
+	if _, err := setupWorktree(cfg, path); err != nil {
+		return err
+	}
+	fmt.Printf("Successfully created worktree for PR #%d on branch '%s' at '%s'\n", number, branch, path)
+	return nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPullRequestConfig(t *testing.T) {
+	cfg := PullRequestConfig{}
+	if cfg.remote() != "origin" || cfg.branchName(42) != "pr-42" {
+		t.Errorf("Unexpected defaults: %q %q", cfg.remote(), cfg.branchName(42))
+	}
+
+	cfg = PullRequestConfig{Branch: "review/{number}", Forge: "gitlab"}
+	if cfg.branchName(7) != "review/7" {
+		t.Errorf("Expected templated branch, got %q", cfg.branchName(7))
+	}
+	refs, err := cfg.refs(7)
+	if err != nil || len(refs) != 1 || refs[0] != "refs/merge-requests/7/head" {
+		t.Errorf("Expected GitLab ref, got %v (%v)", refs, err)
+	}
+
+	refs, _ = PullRequestConfig{}.refs(7)
+	if len(refs) != 2 {
+		t.Errorf("Expected both refs when the forge is unset, got %v", refs)
+	}
+	if _, err := (PullRequestConfig{Forge: "bitbucket"}).refs(7); err == nil {
+		t.Error("Expected error for unknown forge")
+	}
+}
+
+func TestParsePRNumber(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+		hasError bool
+	}{
+		{"12", 12, false},
+		{"#12", 12, false},
+		{" 3 ", 3, false},
+		{"0", 0, true},
+		{"abc", 0, true},
+	}
+
+	for _, test := range tests {
+		number, err := parsePRNumber(test.input)
+		if (err != nil) != test.hasError || number != test.expected {
+			t.Errorf("parsePRNumber(%q) = %d, %v", test.input, number, err)
+		}
+	}
+}
+
+func TestCreatePRWorktreeFromBareRemote(t *testing.T) {
+	repo := newTestRepo(t)
+	origin := filepath.Join(filepath.Dir(repo), "origin.git")
+
+	// Simulate a forge: publish a commit under refs/merge-requests/5/head
+	runGit(t, repo, "checkout", "-b", "contributor")
+	runGit(t, repo, "commit", "--allow-empty", "-m", "contribution")
+	runGit(t, repo, "push", "origin", "contributor:refs/merge-requests/5/head")
+	head := runGit(t, repo, "rev-parse", "HEAD")
+	runGit(t, repo, "checkout", "main")
+	runGit(t, repo, "branch", "-D", "contributor")
+	runGit(t, origin, "rev-parse", "refs/merge-requests/5/head")
+
+	t.Chdir(repo)
+	branch, path, err := createPRWorktree(".", PullRequestConfig{}, 5, createOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if branch != "pr-5" || !strings.HasSuffix(path, "repo-pr-5") {
+		t.Errorf("Unexpected branch/path: %q %q", branch, path)
+	}
+	if got := runGit(t, path, "rev-parse", "HEAD"); got != head {
+		t.Errorf("Expected worktree at PR head %s, got %s", head, got)
+	}
+
+	if _, _, err := createPRWorktree(".", PullRequestConfig{}, 5, createOptions{}); err == nil {
+		t.Error("Expected error when the PR already has a worktree")
+	}
+	if _, _, err := createPRWorktree(".", PullRequestConfig{}, 99, createOptions{}); err == nil {
+		t.Error("Expected error for a PR that doesn't exist")
+	}
+}
+
+func TestCreatePRWorktreeKeepsLocalCommits(t *testing.T) {
+	repo := newTestRepo(t)
+	runGit(t, repo, "push", "origin", "main:refs/pull/7/head")
+	runGit(t, repo, "branch", "pr-7")
+	t.Chdir(repo)
+
+	// A branch the PR head already contains is fast-forwarded
+	if _, _, err := createPRWorktree(".", PullRequestConfig{}, 7, createOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	runGit(t, repo, "worktree", "remove", filepath.Join(filepath.Dir(repo), "repo-pr-7"))
+
+	runGit(t, repo, "checkout", "pr-7")
+	runGit(t, repo, "commit", "--allow-empty", "-m", "local work")
+	local := runGit(t, repo, "rev-parse", "HEAD")
+	runGit(t, repo, "checkout", "main")
+
+	if _, _, err := createPRWorktree(".", PullRequestConfig{}, 7, createOptions{}); err == nil {
+		t.Fatal("Expected an error for a branch with local commits")
+	}
+	if got := runGit(t, repo, "rev-parse", "pr-7"); got != local {
+		t.Errorf("Expected pr-7 to keep its local commit %s, got %s", local, got)
+	}
+}
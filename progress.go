@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// gitProgressMsg reports a progress line from a running git operation.
+// ch is the stream the remaining messages arrive on.
+type gitProgressMsg struct {
+	op      string
+	phase   string
+	percent int
+	ch      <-chan tea.Msg
+}
+
+// gitOpDoneMsg is sent once a streamed git operation has exited.
+type gitOpDoneMsg struct {
+	op  string
+	err error
+}
+
+// progressLine matches git's --progress output such as
+// "Receiving objects:  45% (123/273), 1.2 MiB | 2.0 MiB/s".
+var progressLine = regexp.MustCompile(`^(?:remote:\s*)?([A-Za-z][A-Za-z ]*):\s+(\d+)%`)
+
+// countLine matches progress without a percentage, such as
+// "Enumerating objects: 5, done.".
+var countLine = regexp.MustCompile(`^(?:remote:\s*)?[A-Za-z][A-Za-z ]*:\s+\d+(?:, done)?\.?$`)
+
+func parseProgressLine(line string) (phase string, percent int, ok bool) {
+	match := progressLine.FindStringSubmatch(strings.TrimSpace(line))
+	if match == nil {
+		return "", 0, false
+	}
+	percent, err := strconv.Atoi(match[2])
+	if err != nil {
+		return "", 0, false
+	}
+	return match[1], percent, true
+}
+
+// scanProgressLines splits on both \r and \n, since git redraws progress
+// lines in place with carriage returns.
+func scanProgressLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// streamGit runs git in dir and streams parsed progress from its stderr.
+// The channel always ends with a gitOpDoneMsg.
+func streamGit(op, dir string, args ...string) <-chan tea.Msg {
+	ch := make(chan tea.Msg)
+
+	go func() {
+		defer close(ch)
+
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		stderr, err := cmd.StderrPipe()
+		if err != nil {
+			ch <- gitOpDoneMsg{op: op, err: err}
+			return
+		}
+		if err := cmd.Start(); err != nil {
+			ch <- gitOpDoneMsg{op: op, err: err}
+			return
+		}
+
+		// The last line that isn't progress explains a failure
+		var lastLine string
+		scanner := bufio.NewScanner(stderr)
+		scanner.Split(scanProgressLines)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			if phase, percent, ok := parseProgressLine(line); ok {
+				ch <- gitProgressMsg{op: op, phase: phase, percent: percent, ch: ch}
+				continue
+			}
+			if !countLine.MatchString(line) {
+				lastLine = line
+			}
+		}
+
+		err = cmd.Wait()
+		if err != nil && lastLine != "" {
+			err = fmt.Errorf("%w: %s", err, lastLine)
+		}
+		ch <- gitOpDoneMsg{op: op, err: err}
+	}()
+
+	return ch
+}
+
+func waitForGitOp(ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+func fetchAllCmd(repo string) tea.Cmd {
+	return waitForGitOp(streamGit("Fetch", repo, "fetch", "--all", "--prune", "--progress"))
+}
+
+func pullWorktreeCmd(worktree Worktree) tea.Cmd {
+	return waitForGitOp(streamGit("Pull", worktree.Path, "pull", "--progress"))
+}
+
+// pushRemote is the remote a branch without upstream is pushed to: its
+// branch.<name>.pushRemote, remote.pushDefault, the only remote, or origin
+// when there are several.
+func pushRemote(dir, branch string) (string, error) {
+	for _, key := range []string{"branch." + branch + ".pushRemote", "remote.pushDefault"} {
+		if remote, err := gitIn(dir, "config", "--get", key); err == nil && remote != "" {
+			return remote, nil
+		}
+	}
+	output, err := gitIn(dir, "remote")
+	if err != nil {
+		return "", err
+	}
+	remotes := strings.Fields(output)
+	switch {
+	case len(remotes) == 0:
+		return "", fmt.Errorf("no remote to push '%s' to", branch)
+	case len(remotes) == 1:
+		return remotes[0], nil
+	}
+	for _, remote := range remotes {
+		if remote == "origin" {
+			return remote, nil
+		}
+	}
+	return "", fmt.Errorf("several remotes and no origin; set remote.pushDefault to push '%s'", branch)
+}
+
+// pushWorktreeCmd pushes the worktree's branch, setting the upstream on
+// its push remote when the branch doesn't track anything yet.
+func pushWorktreeCmd(worktree Worktree) tea.Cmd {
+	return func() tea.Msg {
+		args := []string{"push", "--progress"}
+		if worktree.Upstream == "" {
+			remote, err := pushRemote(worktree.Path, worktree.Branch)
+			if err != nil {
+				return gitOpDoneMsg{op: "Push", err: err}
+			}
+			args = append(args, "--set-upstream", remote, worktree.Branch)
+		}
+		return waitForGitOp(streamGit("Push", worktree.Path, args...))()
+	}
+}
+
+// renderProgressBar draws a fixed width ASCII bar for percent.
+func renderProgressBar(percent, width int) string {
+	if percent < 0 {
+		percent = 0
+	}
+	if percent > 100 {
+		percent = 100
+	}
+	filled := percent * width / 100
+	return "[" + strings.Repeat("#", filled) + strings.Repeat("-", width-filled) + "]"
+}
+
+func (m model) startFetch() (model, tea.Cmd) {
+	if m.gitOp != "" {
+		return m, nil
+	}
+	m.gitOp = "Fetch"
+	m.gitPhase = "contacting remotes"
+	return m, fetchAllCmd(m.repo)
+}
+
+func (m model) startPull(worktree Worktree) (model, tea.Cmd) {
+	if m.gitOp != "" {
+		return m, nil
+	}
+	if worktree.Branch == "" {
+		m.statusMessage = fmt.Sprintf("%s Cannot pull a detached worktree", markers.Error)
+		return m, clearStatusAfterDelay()
+	}
+	m.gitOp = "Pull"
+	m.gitPhase = worktree.Branch
+	return m, pullWorktreeCmd(worktree)
+}
+
+func (m model) startPush(worktree Worktree) (model, tea.Cmd) {
+	if m.gitOp != "" {
+		return m, nil
+	}
+	if worktree.Branch == "" {
+		m.statusMessage = fmt.Sprintf("%s Cannot push a detached worktree", markers.Error)
+		return m, clearStatusAfterDelay()
+	}
+	m.gitOp = "Push"
+	m.gitPhase = worktree.Branch
+	return m, pushWorktreeCmd(worktree)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bufio"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseProgressLine(t *testing.T) {
+	tests := []struct {
+		input   string
+		phase   string
+		percent int
+		ok      bool
+	}{
+		{"Receiving objects:  45% (123/273), 1.2 MiB | 2.0 MiB/s", "Receiving objects", 45, true},
+		{"remote: Counting objects: 100% (12/12), done.", "Counting objects", 100, true},
+		{"Resolving deltas:   3% (1/30)", "Resolving deltas", 3, true},
+		{"From ../origin", "", 0, false},
+		{" * [new branch]      feature    -> origin/feature", "", 0, false},
+	}
+
+	for _, test := range tests {
+		phase, percent, ok := parseProgressLine(test.input)
+		if ok != test.ok || phase != test.phase || percent != test.percent {
+			t.Errorf("parseProgressLine(%q) = %q, %d, %v; expected %q, %d, %v",
+				test.input, phase, percent, ok, test.phase, test.percent, test.ok)
+		}
+	}
+}
+
+func TestScanProgressLines(t *testing.T) {
+	scanner := bufio.NewScanner(strings.NewReader("a: 1%\ra: 50%\ra: 100%, done.\nnext"))
+	scanner.Split(scanProgressLines)
+
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	expected := []string{"a: 1%", "a: 50%", "a: 100%, done.", "next"}
+	if strings.Join(lines, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected %q, got %q", expected, lines)
+	}
+}
+
+func TestRenderProgressBar(t *testing.T) {
+	if got := renderProgressBar(50, 10); got != "[#####-----]" {
+		t.Errorf("Expected half-filled bar, got %q", got)
+	}
+	if got := renderProgressBar(150, 4); got != "[####]" {
+		t.Errorf("Expected clamped bar, got %q", got)
+	}
+}
+
+func TestStreamGitFetchAndPush(t *testing.T) {
+	repo := newTestRepo(t)
+
+	// A branch pushed from a second clone only shows up after a fetch
+	other := filepath.Join(filepath.Dir(repo), "other")
+	runGit(t, filepath.Dir(repo), "clone", filepath.Join(filepath.Dir(repo), "origin.git"), other)
+	runGit(t, other, "checkout", "-b", "from-elsewhere")
+	runGit(t, other, "commit", "--allow-empty", "-m", "elsewhere")
+	runGit(t, other, "push", "origin", "from-elsewhere")
+
+	var done gitOpDoneMsg
+	for msg := range streamGit("Fetch", repo, "fetch", "--all", "--prune", "--progress") {
+		if d, ok := msg.(gitOpDoneMsg); ok {
+			done = d
+		}
+	}
+	if done.op != "Fetch" || done.err != nil {
+		t.Fatalf("Expected successful fetch, got %+v", done)
+	}
+	runGit(t, repo, "rev-parse", "--verify", "origin/from-elsewhere")
+
+	// Pushing a branch without upstream sets it
+	runGit(t, repo, "checkout", "-b", "local-only")
+	runGit(t, repo, "commit", "--allow-empty", "-m", "local")
+	cmd := pushWorktreeCmd(Worktree{Path: repo, Branch: "local-only"})
+	msg := cmd()
+	for {
+		if progress, ok := msg.(gitProgressMsg); ok {
+			msg = waitForGitOp(progress.ch)()
+			continue
+		}
+		break
+	}
+	if d, ok := msg.(gitOpDoneMsg); !ok || d.err != nil {
+		t.Fatalf("Expected successful push, got %+v", msg)
+	}
+	if upstream := runGit(t, repo, "rev-parse", "--abbrev-ref", "local-only@{upstream}"); upstream != "origin/local-only" {
+		t.Errorf("Expected upstream origin/local-only, got %q", upstream)
+	}
+}
+
+func TestStreamGitErrorSkipsProgress(t *testing.T) {
+	repo := newTestRepo(t)
+
+	// An alias that fails after drawing progress, like a push that breaks
+	// off mid-transfer
+	alias := `alias.fail=!echo "fatal: the remote hung up" >&2; echo "Enumerating objects: 5, done." >&2; printf "Writing objects:  50%% (2/4)\r" >&2; exit 1`
+	var done gitOpDoneMsg
+	for msg := range streamGit("Push", repo, "-c", alias, "fail") {
+		if d, ok := msg.(gitOpDoneMsg); ok {
+			done = d
+		}
+	}
+	if done.err == nil || !strings.HasSuffix(done.err.Error(), "fatal: the remote hung up") {
+		t.Errorf("Expected the error to end with git's message, got %v", done.err)
+	}
+}
+
+func TestPushRemote(t *testing.T) {
+	repo := newTestRepo(t)
+
+	if remote, err := pushRemote(repo, "feature"); err != nil || remote != "origin" {
+		t.Errorf("Expected the only remote, got %q (%v)", remote, err)
+	}
+
+	runGit(t, repo, "remote", "rename", "origin", "upstream")
+	runGit(t, repo, "remote", "add", "fork", filepath.Join(filepath.Dir(repo), "origin.git"))
+	if _, err := pushRemote(repo, "feature"); err == nil || !strings.Contains(err.Error(), "remote.pushDefault") {
+		t.Errorf("Expected several remotes without origin to be refused, got %v", err)
+	}
+
+	runGit(t, repo, "config", "remote.pushDefault", "fork")
+	if remote, _ := pushRemote(repo, "feature"); remote != "fork" {
+		t.Errorf("Expected remote.pushDefault, got %q", remote)
+	}
+	runGit(t, repo, "config", "branch.feature.pushRemote", "upstream")
+	if remote, _ := pushRemote(repo, "feature"); remote != "upstream" {
+		t.Errorf("Expected the branch's push remote, got %q", remote)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// promoteChanges branches branchName off the current HEAD of the worktree
+// at source, moves all its uncommitted changes, untracked files included,
+// into a new worktree for that branch and leaves source clean. Either all
+// of it happens or nothing does.
+func promoteChanges(source, branchName string, opts createOptions) (string, error) {
+	if !isWorktreeDirty(source) {
+		return "", errNothingToMove
+	}
+	return moveChangesToNewBranch(source, branchName, true, opts)
+}
+
+func promoteChangesCmd(source, branchName string, opts createOptions) tea.Cmd {
+	return func() tea.Msg {
+		path, err := promoteChanges(source, branchName, opts)
+		return resultMsg(newBranchCreatedMsg{path: path}, err)
+	}
+}
+
+// startPromote asks for the name of the branch that takes over the changes
+// of worktree, using the new branch input and its naming rules.
+func (m *model) startPromote(worktree Worktree) tea.Cmd {
+	if !worktree.Dirty {
+		m.statusMessage = fmt.Sprintf("%s No uncommitted changes in %s", markers.Error, filepath.Base(worktree.Path))
+		return clearStatusAfterDelay()
+	}
+	m.switchView("branches")
+	cmd := m.startNewBranch()
+	if m.creatingBranch {
+		m.promoteSource = worktree.Path
+	}
+	return cmd
+}
+
+// runPromoteCommand promotes the changes of the current worktree.
+func runPromoteCommand(cfg Config, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: wtree promote <new branch>")
+	}
+	branchName := args[0]
+
+	source, err := getRepoRoot(".")
+	if err != nil {
+		return err
+	}
+
+	branches, err := getBranches(".")
+	if err != nil {
+		return err
+	}
+	rules, err := loadNamingRules(".", cfg)
+	if err != nil {
+		return err
+	}
+	if err := checkNewBranch(".", branchName, branches, rules); err != nil {
+		return err
+	}
+
+	opts, err := newCreateOptions(".", cfg, defaultSparse(".", cfg))
+	if err != nil {
+		return err
+	}
+	path, err := promoteChanges(source, branchName, opts)
+	if err = warnMetadata(err); err != nil {
+		return err
+	}
+	if _, err := setupWorktree(cfg, path); err != nil {
+		return err
+	}
+	fmt.Printf("Moved changes to new branch '%s' in '%s'\n", branchName, path)
+	return nil
+}
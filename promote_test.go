@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPromoteChanges(t *testing.T) {
+	repo := newTestRepo(t)
+	t.Chdir(repo)
+
+	// A local commit the origin doesn't have yet must carry over
+	writeFile(t, filepath.Join(repo, "local.txt"), "local\n")
+	runGit(t, repo, "add", "local.txt")
+	runGit(t, repo, "commit", "-m", "local work")
+	head := runGit(t, repo, "rev-parse", "HEAD")
+
+	writeFile(t, filepath.Join(repo, "README"), "hello\nfix\n")
+	writeFile(t, filepath.Join(repo, "new.txt"), "new\n")
+
+	path, err := promoteChanges(repo, "fix/promoted", createOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if base := runGit(t, path, "rev-parse", "HEAD"); base != head {
+		t.Errorf("Expected the new branch at %s, got %s", head, base)
+	}
+	if content := readFile(t, filepath.Join(path, "README")); content != "hello\nfix\n" {
+		t.Errorf("Expected the modification in the new worktree, got %q", content)
+	}
+	if content := readFile(t, filepath.Join(path, "new.txt")); content != "new\n" {
+		t.Errorf("Expected the untracked file in the new worktree, got %q", content)
+	}
+	if status := runGit(t, repo, "status", "--porcelain"); status != "" {
+		t.Errorf("Expected the original tree to be clean, got %q", status)
+	}
+	if branch := runGit(t, repo, "branch", "--show-current"); branch != "main" {
+		t.Errorf("Expected the original tree to stay on main, got %q", branch)
+	}
+}
+
+func TestPromoteChangesLeavesTreeOnFailure(t *testing.T) {
+	repo := newTestRepo(t)
+	t.Chdir(repo)
+
+	if _, err := promoteChanges(repo, "fix/clean", createOptions{}); !errors.Is(err, errNothingToMove) {
+		t.Errorf("Expected a clean tree to have nothing to promote, got %v", err)
+	}
+
+	runGit(t, repo, "branch", "taken")
+	writeFile(t, filepath.Join(repo, "README"), "changed\n")
+	if _, err := promoteChanges(repo, "taken", createOptions{}); err == nil {
+		t.Fatal("Expected an existing branch name to fail")
+	}
+	if content := readFile(t, filepath.Join(repo, "README")); content != "changed\n" {
+		t.Errorf("Expected the changes to stay put, got %q", content)
+	}
+}
+
+func TestStartPromote(t *testing.T) {
+	m := initialModel()
+	m.worktrees = []Worktree{{Path: "/src/repo"}}
+
+	m.startPromote(m.worktrees[0])
+	if m.creatingBranch {
+		t.Error("Expected a clean worktree not to open the branch input")
+	}
+
+	m.worktrees[0].Dirty = true
+	m.startPromote(m.worktrees[0])
+	if !m.creatingBranch || m.promoteSource != "/src/repo" || m.view != "branches" {
+		t.Errorf("Expected the branch input for the promoted changes, got creating=%v source=%q view=%q",
+			m.creatingBranch, m.promoteSource, m.view)
+	}
+}
+
+func TestPlainNewBranchAfterFailedPromote(t *testing.T) {
+	m := initialModel()
+	m.worktrees = []Worktree{{Path: "/src/repo", Dirty: true}}
+	m.startPromote(m.worktrees[0])
+	m.newBranchInput.SetValue("feature/promoted")
+	m, _ = m.confirmNewBranch()
+	if !m.creatingNewBranch {
+		t.Fatal("Expected the promotion to start")
+	}
+
+	updated, _ := m.Update(errors.New("changes conflict"))
+	m = updated.(model)
+	if m.promoteSource != "" || m.creatingBranch {
+		t.Fatalf("Expected the failed promotion to be forgotten, got source=%q creating=%v", m.promoteSource, m.creatingBranch)
+	}
+
+	m.startNewBranch()
+	m.newBranchInput.SetValue("feature/plain")
+	_, cmd := m.confirmNewBranch()
+	if creating, ok := cmd().(newBranchCreatingMsg); !ok || creating.branchName != "feature/plain" {
+		t.Errorf("Expected a plain new branch, got %#v", cmd())
+	}
+	if view := m.View(); strings.Contains(view, "changes of") {
+		t.Errorf("Expected the plain new branch prompt, got:\n%s", view)
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// validSortOrder reports whether order is a supported worktree order.
+func validSortOrder(order string) bool {
+	return order == "" || order == "recent" || order == "git"
+}
+
+// lastUsed is when the worktree was last opened, or else created.
+func lastUsed(meta WorktreeMeta) time.Time {
+	if !meta.LastOpened.IsZero() {
+		return meta.LastOpened
+	}
+	return meta.CreatedAt
+}
+
+// sortWorktrees puts pinned worktrees first. With the "recent" order the
+// rest follow most recently used first; worktrees wtree knows nothing
+// about keep the order of `git worktree list` at the end.
+func sortWorktrees(worktrees []Worktree, order string) []Worktree {
+	sorted := make([]Worktree, len(worktrees))
+	copy(sorted, worktrees)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].Meta, sorted[j].Meta
+		if a.Pinned != b.Pinned {
+			return a.Pinned
+		}
+		if order == "git" {
+			return false
+		}
+		return lastUsed(a).After(lastUsed(b))
+	})
+	return sorted
+}
+
+// recentWorktrees returns up to n worktrees, most recently opened first.
+func recentWorktrees(repo string, n int) ([]Worktree, error) {
+	worktrees, err := getWorktrees(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var opened []Worktree
+	for _, worktree := range worktrees {
+		if !worktree.Meta.LastOpened.IsZero() {
+			opened = append(opened, worktree)
+		}
+	}
+	sort.SliceStable(opened, func(i, j int) bool {
+		return opened[i].Meta.LastOpened.After(opened[j].Meta.LastOpened)
+	})
+	if len(opened) > n {
+		opened = opened[:n]
+	}
+	return opened, nil
+}
+
+type worktreePinnedMsg struct {
+	path   string
+	pinned bool
+}
+
+func togglePinCmd(repo string, worktree Worktree) tea.Cmd {
+	return func() tea.Msg {
+		pinned := !worktree.Meta.Pinned
+		err := editWorktreeMeta(repo, worktree.Path, func(meta *WorktreeMeta) {
+			meta.Pinned = pinned
+		})
+		if err != nil {
+			return err
+		}
+		return worktreePinnedMsg{path: worktree.Path, pinned: pinned}
+	}
+}
+
+// runRecentCommand lists the last opened worktrees. Given a position it
+// prints that worktree's path instead and counts it as opened, so a shell
+// function can `cd "$(wtree recent 2)"`.
+func runRecentCommand(cfg Config, args []string) error {
+	flags := flag.NewFlagSet("recent", flag.ContinueOnError)
+	count := flags.Int("n", 10, "Number of worktrees to list")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 1 {
+		return fmt.Errorf("usage: wtree recent [-n count] [position]")
+	}
+
+	position := 0
+	if flags.NArg() == 1 {
+		n, err := strconv.Atoi(flags.Arg(0))
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid position '%s'", flags.Arg(0))
+		}
+		position = n
+		if position > *count {
+			*count = position
+		}
+	}
+
+	worktrees, err := recentWorktrees(".", *count)
+	if err != nil {
+		return err
+	}
+
+	if position > 0 {
+		if position > len(worktrees) {
+			return fmt.Errorf("only %d recently opened worktrees", len(worktrees))
+		}
+		worktree := worktrees[position-1]
+		fmt.Println(worktree.Path)
+		return markOpened(worktree.Path)
+	}
+
+	if len(worktrees) == 0 {
+		fmt.Println("No recently opened worktrees")
+		return nil
+	}
+	now := time.Now()
+	for i, worktree := range worktrees {
+		fmt.Printf("%2d  %-30s %-40s opened %s ago\n", i+1, filepath.Base(worktree.Path),
+			worktreeRef(worktree), humanDuration(now.Sub(worktree.Meta.LastOpened)))
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSortWorktrees(t *testing.T) {
+	now := time.Now()
+	worktrees := []Worktree{
+		{Path: "/src/repo"},
+		{Path: "/src/repo-old", Meta: WorktreeMeta{LastOpened: now.Add(-48 * time.Hour)}},
+		{Path: "/src/repo-new", Meta: WorktreeMeta{CreatedAt: now.Add(-time.Hour)}},
+		{Path: "/src/repo-pinned", Meta: WorktreeMeta{Pinned: true}},
+		{Path: "/src/repo-fresh", Meta: WorktreeMeta{CreatedAt: now.Add(-72 * time.Hour), LastOpened: now}},
+	}
+
+	tests := []struct {
+		order    string
+		expected []string
+	}{
+		{"", []string{"/src/repo-pinned", "/src/repo-fresh", "/src/repo-new", "/src/repo-old", "/src/repo"}},
+		{"git", []string{"/src/repo-pinned", "/src/repo", "/src/repo-old", "/src/repo-new", "/src/repo-fresh"}},
+	}
+	for _, test := range tests {
+		sorted := sortWorktrees(worktrees, test.order)
+		for i, path := range test.expected {
+			if sorted[i].Path != path {
+				t.Errorf("sortWorktrees(%q)[%d] = %s, expected %s", test.order, i, sorted[i].Path, path)
+			}
+		}
+	}
+	if worktrees[0].Path != "/src/repo" {
+		t.Error("Expected sortWorktrees to leave its input alone")
+	}
+}
+
+func TestRecentWorktrees(t *testing.T) {
+	repo := newTestRepo(t)
+	t.Chdir(repo)
+
+	first, err := createNewBranchWorktree(".", "feature/first", createOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := createNewBranchWorktree(".", "feature/second", createOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := createNewBranchWorktree(".", "feature/never", createOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := openWorktreeWith(Worktree{Path: first}, "true"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if err := openWorktreeWith(Worktree{Path: second}, "true"); err != nil {
+		t.Fatal(err)
+	}
+
+	recent, err := recentWorktrees(".", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recent) != 2 || recent[0].Path != second || recent[1].Path != first {
+		t.Fatalf("Expected second then first, got %v", recent)
+	}
+
+	// Jumping to a worktree counts as opening it
+	if err := runRecentCommand(Config{}, []string{"2"}); err != nil {
+		t.Fatal(err)
+	}
+	recent, err = recentWorktrees(".", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recent) != 1 || recent[0].Path != first {
+		t.Errorf("Expected first to be most recent after jumping to it, got %v", recent)
+	}
+
+	if err := runRecentCommand(Config{}, []string{"5"}); err == nil {
+		t.Error("Expected an error for a position past the recent worktrees")
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// watchInterval is how often the repository metadata is checked for
+// changes made outside wtree, e.g. another terminal adding a worktree.
+const watchInterval = 500 * time.Millisecond
+
+type autoRefreshMsg struct{}
+type backgroundFetchedMsg struct{}
+type repoChangedMsg struct{ signature string }
+type repoUnchangedMsg struct{}
+
+// RefreshConfig controls background refreshing of the lists.
+type RefreshConfig struct {
+	Interval string `json:"interval"` // e.g. "5m"; empty or "0" disables the ticker
+	Fetch    bool   `json:"fetch"`    // fetch all remotes on every tick
+	Watch    *bool  `json:"watch"`    // watch .git for external changes, on by default
+}
+
+func (c RefreshConfig) interval() (time.Duration, error) {
+	if c.Interval == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(c.Interval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid auto refresh interval %q: %w", c.Interval, err)
+	}
+	return d, nil
+}
+
+func (c RefreshConfig) watchEnabled() bool {
+	return c.Watch == nil || *c.Watch
+}
+
+func autoRefreshCmd(interval time.Duration) tea.Cmd {
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return autoRefreshMsg{}
+	})
+}
+
+// backgroundFetchCmd fetches quietly; failures such as being offline are
+// ignored and the lists are refreshed from whatever refs exist. Its
+// message isn't tagged with repo: it ends the fetch, which holds gitOp,
+// even when another repository has been opened since.
+func backgroundFetchCmd(repo string) tea.Cmd {
+	return func() tea.Msg {
+		exec.Command("git", "-C", repo, "fetch", "--all", "--prune", "--quiet").Run()
+		return backgroundFetchedMsg{}
+	}
+}
+
+// watchRepoCmd polls the shared git directory of repo and reports when the
+// signature of the watched files differs from the previous one. Its
+// messages are tagged with repo, so the watcher stops once another
+// repository is opened.
+func watchRepoCmd(repo, previous string) tea.Cmd {
+	return inRepo(repo, tea.Tick(watchInterval, func(time.Time) tea.Msg {
+		commonDir, err := getGitCommonDir(repo)
+		if err != nil {
+			return repoUnchangedMsg{}
+		}
+		signature := repoSignature(commonDir)
+		if signature == previous {
+			return repoUnchangedMsg{}
+		}
+		return repoChangedMsg{signature: signature}
+	}))
+}
+
+// repoSignature summarises the files that change when worktrees or refs
+// are added, removed or moved. Index files are deliberately left out:
+// `git status` rewrites them, which would make every refresh trigger
+// another one.
+func repoSignature(commonDir string) string {
+	var sig strings.Builder
+	add := func(path string) {
+		info, err := os.Stat(path)
+		if err != nil {
+			return
+		}
+		fmt.Fprintf(&sig, "%s:%d:%d;", path, info.ModTime().UnixNano(), info.Size())
+	}
+
+	add(filepath.Join(commonDir, "HEAD"))
+	add(filepath.Join(commonDir, "packed-refs"))
+	// Dropping an older stash only rewrites the stash reflog
+	add(filepath.Join(commonDir, "logs", "refs", "stash"))
+
+	filepath.WalkDir(filepath.Join(commonDir, "refs"), func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			add(path)
+		}
+		return nil
+	})
+
+	worktreesDir := filepath.Join(commonDir, "worktrees")
+	add(worktreesDir)
+	if entries, err := os.ReadDir(worktreesDir); err == nil {
+		for _, entry := range entries {
+			add(filepath.Join(worktreesDir, entry.Name(), "HEAD"))
+		}
+	}
+
+	return sig.String()
+}
+
+// startRefreshTimers returns the commands that start the background
+// refresh ticker and the repository watcher, as enabled in the config.
+func (m model) startRefreshTimers() []tea.Cmd {
+	var cmds []tea.Cmd
+	if interval, _ := m.config.Refresh.interval(); interval > 0 {
+		cmds = append(cmds, autoRefreshCmd(interval))
+	}
+	if m.config.Refresh.watchEnabled() {
+		cmds = append(cmds, watchRepoCmd(m.repo, ""))
+	}
+	return cmds
+}
+
+func (m model) handleRefreshMsg(msg tea.Msg) (model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case autoRefreshMsg:
+		interval, _ := m.config.Refresh.interval()
+		next := autoRefreshCmd(interval)
+		if m.config.Refresh.Fetch && m.gitOp == "" {
+			// Holding gitOp keeps a manual fetch, pull or push from
+			// running alongside
+			m.gitOp = "Fetch (background)"
+			m.gitPhase = "all remotes"
+			return m, tea.Batch(backgroundFetchCmd(m.repo), next)
+		}
+		return m, tea.Batch(getWorktreesCmd(m.repo), getBranchesCmd(m.repo), getTagsCmd(m.repo), getStashesCmd(m.repo), next)
+	case backgroundFetchedMsg:
+		m.gitOp = ""
+		m.gitPhase = ""
+		return m, tea.Batch(getWorktreesCmd(m.repo), getBranchesCmd(m.repo), getTagsCmd(m.repo))
+	case repoChangedMsg:
+		// The first signature is only a baseline
+		first := m.repoSignature == ""
+		m.repoSignature = msg.signature
+		if first {
+			return m, watchRepoCmd(m.repo, m.repoSignature)
+		}
+		return m, tea.Batch(getWorktreesCmd(m.repo), getBranchesCmd(m.repo), getTagsCmd(m.repo), getStashesCmd(m.repo), watchRepoCmd(m.repo, m.repoSignature))
+	case repoUnchangedMsg:
+		return m, watchRepoCmd(m.repo, m.repoSignature)
+	}
+	return m, nil
+}
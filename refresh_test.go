@@ -0,0 +1,79 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRefreshConfig(t *testing.T) {
+	if d, err := (RefreshConfig{}).interval(); err != nil || d != 0 {
+		t.Errorf("Expected no interval by default, got %v (%v)", d, err)
+	}
+	if d, err := (RefreshConfig{Interval: "90s"}).interval(); err != nil || d != 90*time.Second {
+		t.Errorf("Expected 90s, got %v (%v)", d, err)
+	}
+	if _, err := (RefreshConfig{Interval: "soon"}).interval(); err == nil {
+		t.Error("Expected error for invalid interval")
+	}
+
+	off := false
+	if !(RefreshConfig{}).watchEnabled() || (RefreshConfig{Watch: &off}).watchEnabled() {
+		t.Error("Expected watching to default on and be switchable off")
+	}
+}
+
+func TestRepoSignatureTracksWorktreesAndRefs(t *testing.T) {
+	repo := newTestRepo(t)
+	commonDir := filepath.Join(repo, ".git")
+
+	before := repoSignature(commonDir)
+
+	runGit(t, repo, "status")
+	if after := repoSignature(commonDir); after != before {
+		t.Error("Expected git status not to change the signature")
+	}
+
+	runGit(t, repo, "worktree", "add", "-b", "side", filepath.Join(filepath.Dir(repo), "repo-side"))
+	afterWorktree := repoSignature(commonDir)
+	if afterWorktree == before {
+		t.Error("Expected adding a worktree to change the signature")
+	}
+
+	runGit(t, repo, "branch", "nested/branch")
+	if repoSignature(commonDir) == afterWorktree {
+		t.Error("Expected creating a nested branch to change the signature")
+	}
+}
+
+func TestRepoChangedMsgSetsBaseline(t *testing.T) {
+	m := initialModel()
+
+	m, cmd := m.handleRefreshMsg(repoChangedMsg{signature: "a"})
+	if m.repoSignature != "a" || cmd == nil {
+		t.Errorf("Expected first signature to become the baseline, got %q", m.repoSignature)
+	}
+
+	m, cmd = m.handleRefreshMsg(repoChangedMsg{signature: "b"})
+	if m.repoSignature != "b" || cmd == nil {
+		t.Errorf("Expected a change to update the signature and refresh, got %q", m.repoSignature)
+	}
+}
+
+func TestBackgroundFetchHoldsGitOp(t *testing.T) {
+	m := initialModel()
+	m.config.Refresh = RefreshConfig{Interval: "5m", Fetch: true}
+
+	m, _ = m.handleRefreshMsg(autoRefreshMsg{})
+	if m.gitOp == "" {
+		t.Fatal("Expected the background fetch to hold gitOp")
+	}
+	if next, cmd := m.startFetch(); cmd != nil || next.gitOp != m.gitOp {
+		t.Error("Expected a manual fetch not to start during the background fetch")
+	}
+
+	m, _ = m.handleRefreshMsg(backgroundFetchedMsg{})
+	if m.gitOp != "" {
+		t.Errorf("Expected the finished fetch to release gitOp, got %q", m.gitOp)
+	}
+}
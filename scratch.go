@@ -0,0 +1,231 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const defaultScratchTTL = 72 * time.Hour
+
+// ScratchConfig configures throwaway worktrees.
+type ScratchConfig struct {
+	TTL    string `json:"ttl"`    // Go duration, defaults to "72h"
+	Branch bool   `json:"branch"` // create a scratch/<time> branch instead of detaching
+}
+
+func (c ScratchConfig) ttl() (time.Duration, error) {
+	if c.TTL == "" {
+		return defaultScratchTTL, nil
+	}
+	ttl, err := time.ParseDuration(c.TTL)
+	if err != nil || ttl <= 0 {
+		return 0, fmt.Errorf("invalid scratch ttl %q", c.TTL)
+	}
+	return ttl, nil
+}
+
+// createScratchWorktree creates a worktree from the origin main branch (or
+// HEAD when there is none) and records when it expires.
+func createScratchWorktree(repo string, cfg ScratchConfig, now time.Time, opts createOptions) (string, error) {
+	ttl, err := cfg.ttl()
+	if err != nil {
+		return "", err
+	}
+
+	base, err := getOriginMainBranch(repo)
+	if err != nil {
+		base = "HEAD"
+	}
+
+	stamp := now.Format("20060102-150405")
+	var path string
+	if cfg.Branch {
+		path, err = createNewBranchWorktreeFrom(repo, "scratch/"+stamp, base, opts)
+	} else {
+		path, err = createDetachedWorktree(repo, base, "scratch-"+stamp, opts)
+	}
+	if err != nil && !isMetadataError(err) {
+		return "", err
+	}
+
+	expiryErr := editWorktreeMeta(repo, path, func(meta *WorktreeMeta) {
+		meta.Scratch = true
+		meta.ExpiresAt = now.Add(ttl)
+	})
+	if expiryErr != nil {
+		err = metadataError{expiryErr}
+	}
+	return path, err
+}
+
+// unsavedWork describes work in worktree that removing it would lose:
+// uncommitted changes, or commits that no other branch, tag or remote
+// contains. It returns "" when the worktree is safe to remove.
+func unsavedWork(worktree Worktree) string {
+	if isWorktreeDirty(worktree.Path) {
+		return "uncommitted changes"
+	}
+
+	args := []string{"-C", worktree.Path, "rev-list", "--count", "HEAD", "--not"}
+	if worktree.Branch != "" {
+		// The worktree's own branch goes away with it
+		args = append(args, "--exclude="+worktree.Branch)
+	}
+	args = append(args, "--branches", "--tags", "--remotes")
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return "could not check for unpushed commits"
+	}
+	if count := strings.TrimSpace(string(output)); count != "0" {
+		return count + " commits not on any other branch, tag or remote"
+	}
+	return ""
+}
+
+// removeScratchWorktree removes a worktree along with its scratch branch.
+func removeScratchWorktree(repo string, worktree Worktree) error {
+	err := deleteWorktree(repo, worktree)
+	if err != nil && !isMetadataError(err) {
+		return err
+	}
+	if strings.HasPrefix(worktree.Branch, "scratch/") {
+		// Already checked for unsaved work, so force deleting is safe
+		if err := exec.Command("git", "-C", repo, "branch", "-D", worktree.Branch).Run(); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// gcResult lists expired worktrees that were removed and those skipped
+// because they still hold work, with the reason.
+type gcResult struct {
+	removed []string
+	skipped map[string]string
+}
+
+// collectExpiredWorktrees removes expired worktrees that have no unsaved
+// work. With dryRun set nothing is removed.
+func collectExpiredWorktrees(repo string, now time.Time, dryRun bool) (gcResult, error) {
+	result := gcResult{skipped: make(map[string]string)}
+
+	worktrees, err := getWorktrees(repo)
+	if err != nil {
+		return result, err
+	}
+
+	for _, worktree := range worktrees {
+		if !worktree.Meta.expired(now) {
+			continue
+		}
+		if reason := unsavedWork(worktree); reason != "" {
+			result.skipped[worktree.Path] = reason
+			continue
+		}
+		if !dryRun {
+			// Stale metadata doesn't keep a removed worktree around
+			if err := removeScratchWorktree(repo, worktree); err != nil && !isMetadataError(err) {
+				result.skipped[worktree.Path] = err.Error()
+				continue
+			}
+		}
+		result.removed = append(result.removed, worktree.Path)
+	}
+	return result, nil
+}
+
+type scratchCreatedMsg struct{ path string }
+type gcFinishedMsg struct{ result gcResult }
+
+func createScratchWorktreeCmd(repo string, cfg ScratchConfig, opts createOptions) tea.Cmd {
+	return func() tea.Msg {
+		path, err := createScratchWorktree(repo, cfg, time.Now(), opts)
+		return resultMsg(scratchCreatedMsg{path: path}, err)
+	}
+}
+
+func collectExpiredWorktreesCmd(repo string) tea.Cmd {
+	return func() tea.Msg {
+		result, err := collectExpiredWorktrees(repo, time.Now(), false)
+		if err != nil {
+			return err
+		}
+		return gcFinishedMsg{result: result}
+	}
+}
+
+func (m model) startScratch() (model, tea.Cmd) {
+	opts, err := m.worktreeOptions(m.newSparse)
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("%s %v", markers.Error, err)
+		return m, clearStatusAfterDelay()
+	}
+	m.creatingWorktree = true
+	m.creatingForBranch = "scratch"
+	return m, createScratchWorktreeCmd(m.repo, m.config.Scratch, opts)
+}
+
+// renderExpiry describes when a scratch worktree expires.
+func renderExpiry(meta WorktreeMeta, now time.Time) string {
+	if meta.ExpiresAt.IsZero() {
+		return ""
+	}
+	if meta.expired(now) {
+		return errorStyle.UnsetPaddingLeft().Render("expired")
+	}
+	return pathStyle.Render("scratch · expires in " + humanDuration(meta.ExpiresAt.Sub(now)))
+}
+
+func runScratchCommand(cfg Config, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("usage: wtree scratch")
+	}
+	opts, err := newCreateOptions(".", cfg, defaultSparse(".", cfg))
+	if err != nil {
+		return err
+	}
+	path, err := createScratchWorktree(".", cfg.Scratch, time.Now(), opts)
+	if err = warnMetadata(err); err != nil {
+		return err
+	}
+	if _, err := setupWorktree(cfg, path); err != nil {
+		return err
+	}
+	ttl, _ := cfg.Scratch.ttl()
+	fmt.Printf("Successfully created scratch worktree at '%s' (expires in %s)\n", path, ttl)
+	return nil
+}
+
+func runGCCommand(cfg Config, args []string) error {
+	flags := flag.NewFlagSet("gc", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "Only list the worktrees that would be removed")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	result, err := collectExpiredWorktrees(".", time.Now(), *dryRun)
+	if err != nil {
+		return err
+	}
+
+	verb := "Removed"
+	if *dryRun {
+		verb = "Would remove"
+	}
+	for _, path := range result.removed {
+		fmt.Printf("%s %s\n", verb, path)
+	}
+	for path, reason := range result.skipped {
+		fmt.Printf("Kept %s: %s\n", filepath.Base(path), reason)
+	}
+	if len(result.removed) == 0 && len(result.skipped) == 0 {
+		fmt.Println("No expired worktrees")
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScratchWorktreesExpireAndCollect(t *testing.T) {
+	repo := newTestRepo(t)
+	t.Chdir(repo)
+
+	now := time.Now()
+	old := now.Add(-100 * time.Hour)
+
+	clean, err := createScratchWorktree(".", ScratchConfig{}, old, createOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dirty, err := createScratchWorktree(".", ScratchConfig{}, old.Add(time.Second), createOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unpushed, err := createScratchWorktree(".", ScratchConfig{Branch: true}, old.Add(2*time.Second), createOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fresh, err := createScratchWorktree(".", ScratchConfig{TTL: "1h"}, now, createOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dirty, "notes.txt"), []byte("wip\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, unpushed, "commit", "--allow-empty", "-m", "experiment")
+
+	dryRun, err := collectExpiredWorktrees(".", now, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dryRun.removed) != 1 || dryRun.removed[0] != clean {
+		t.Fatalf("Expected only the clean worktree to be collectable, got %+v", dryRun)
+	}
+	if _, err := os.Stat(clean); err != nil {
+		t.Fatal("Expected a dry run to leave the worktree in place")
+	}
+
+	result, err := collectExpiredWorktrees(".", now, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.removed) != 1 || result.removed[0] != clean {
+		t.Errorf("Expected the clean worktree removed, got %+v", result)
+	}
+	if !strings.Contains(result.skipped[dirty], "uncommitted") {
+		t.Errorf("Expected the dirty worktree kept, got %q", result.skipped[dirty])
+	}
+	if !strings.Contains(result.skipped[unpushed], "1 commits") {
+		t.Errorf("Expected the unpushed worktree kept, got %q", result.skipped[unpushed])
+	}
+	if _, ok := result.skipped[fresh]; ok {
+		t.Error("Expected an unexpired worktree to be ignored")
+	}
+
+	if _, err := os.Stat(clean); !os.IsNotExist(err) {
+		t.Error("Expected the clean worktree directory to be gone")
+	}
+	metadata, err := loadMetadata(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := metadata[clean]; ok {
+		t.Error("Expected metadata of the removed worktree to be dropped")
+	}
+	if !metadata[fresh].Scratch || metadata[fresh].expired(now) {
+		t.Errorf("Expected fresh scratch metadata, got %+v", metadata[fresh])
+	}
+}
+
+func TestRenderExpiry(t *testing.T) {
+	now := time.Now()
+	tests := map[time.Duration]string{
+		-time.Minute:     "expired",
+		30 * time.Minute: "expires in 29m",
+		5 * time.Hour:    "expires in 4h",
+		72 * time.Hour:   "expires in 2d",
+	}
+	for left, expected := range tests {
+		meta := WorktreeMeta{Scratch: true, ExpiresAt: now.Add(left)}
+		if rendered := renderExpiry(meta, now.Add(time.Second)); !strings.Contains(rendered, expected) {
+			t.Errorf("renderExpiry(%s) = %q, expected %q", left, rendered, expected)
+		}
+	}
+	if renderExpiry(WorktreeMeta{}, now) != "" {
+		t.Error("Expected nothing for worktrees without a TTL")
+	}
+}
+
+func TestScratchConfigTTL(t *testing.T) {
+	if ttl, err := (ScratchConfig{}).ttl(); err != nil || ttl != defaultScratchTTL {
+		t.Errorf("Expected default TTL, got %s (%v)", ttl, err)
+	}
+	if _, err := (ScratchConfig{TTL: "soon"}).ttl(); err == nil {
+		t.Error("Expected an invalid TTL to be rejected")
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+// Selection is tracked by identity rather than index so that refreshing or
+// re-filtering a list keeps the cursor on the same worktree, branch or stash.
+
+func branchID(branch Branch) string {
+	return branch.Type + ":" + branch.Name
+}
+
+// selectionID returns the identity of the item under the cursor in the
+// given view, or "" if that view isn't showing or is empty.
+func (m model) selectionID(view string) string {
+	if m.view != view {
+		return ""
+	}
+	if view == "worktrees" && m.cursor < len(m.worktrees) {
+		return m.worktrees[m.cursor].Path
+	}
+	if view == "branches" && m.cursor < len(m.branches) {
+		return branchID(m.branches[m.cursor])
+	}
+	if view == "tags" && m.cursor < len(m.tags) {
+		return branchID(m.tags[m.cursor])
+	}
+	if view == "stashes" && m.cursor < len(m.stashes) {
+		return m.stashes[m.cursor].Commit
+	}
+	return ""
+}
+
+// restoreSelection moves the cursor back onto the item with the given
+// identity after view's list changed. When the item is gone the cursor
+// keeps its index, clamped to the list, so deleting a row selects the
+// next one.
+func (m *model) restoreSelection(view, id string) {
+	if m.view != view {
+		return
+	}
+
+	if id != "" {
+		for i := 0; i < m.listLen(); i++ {
+			if m.itemID(i) == id {
+				m.cursor = i
+				m.adjustScrollOffset()
+				return
+			}
+		}
+	}
+
+	if m.cursor >= m.listLen() {
+		m.cursor = m.listLen() - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+	m.adjustScrollOffset()
+}
+
+func (m model) itemID(index int) string {
+	if m.view == "worktrees" {
+		return m.worktrees[index].Path
+	}
+	if m.view == "stashes" {
+		return m.stashes[index].Commit
+	}
+	if m.view == "tags" {
+		return branchID(m.tags[index])
+	}
+	return branchID(m.branches[index])
+}
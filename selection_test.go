@@ -0,0 +1,86 @@
+package main
+
+import "testing"
+
+func TestRefreshKeepsWorktreeSelection(t *testing.T) {
+	m := initialModel()
+	newModel, _ := m.Update(worktreesMsg{
+		{Path: "/src/a", Branch: "a"},
+		{Path: "/src/b", Branch: "b"},
+		{Path: "/src/c", Branch: "c"},
+	})
+	m = newModel.(model)
+	m.cursor = 2
+
+	// Another worktree appears before the selected one
+	newModel, _ = m.Update(worktreesMsg{
+		{Path: "/src/0", Branch: "0"},
+		{Path: "/src/a", Branch: "a"},
+		{Path: "/src/b", Branch: "b"},
+		{Path: "/src/c", Branch: "c"},
+	})
+	m = newModel.(model)
+	if m.worktrees[m.cursor].Path != "/src/c" {
+		t.Errorf("Expected cursor to stay on /src/c, got %q", m.worktrees[m.cursor].Path)
+	}
+
+	// The selected worktree disappears: the cursor stays in range
+	newModel, _ = m.Update(worktreesMsg{
+		{Path: "/src/0", Branch: "0"},
+		{Path: "/src/a", Branch: "a"},
+	})
+	m = newModel.(model)
+	if m.cursor != 1 {
+		t.Errorf("Expected cursor to be clamped to 1, got %d", m.cursor)
+	}
+}
+
+func TestRefreshKeepsBranchSelection(t *testing.T) {
+	m := initialModel()
+	m.view = "branches"
+	newModel, _ := m.Update(branchesMsg{
+		{Name: "main", Type: "local"},
+		{Name: "feature", Type: "local"},
+	})
+	m = newModel.(model)
+	m.cursor = 1
+
+	newModel, _ = m.Update(branchesMsg{
+		{Name: "hotfix", Type: "local"},
+		{Name: "main", Type: "local"},
+		{Name: "feature", Type: "local"},
+	})
+	m = newModel.(model)
+	if m.branches[m.cursor].Name != "feature" {
+		t.Errorf("Expected cursor to stay on feature, got %q", m.branches[m.cursor].Name)
+	}
+
+	// Narrowing the filter keeps the selected branch when it still matches
+	m.filterInput.SetValue("feat")
+	m.filterBranches()
+	if m.branches[m.cursor].Name != "feature" {
+		t.Errorf("Expected filtered cursor on feature, got %q", m.branches[m.cursor].Name)
+	}
+}
+
+func TestCursorLandsOnCreatedWorktree(t *testing.T) {
+	m := initialModel()
+	m.view = "branches"
+	m.creatingWorktree = true
+
+	newModel, _ := m.Update(worktreeCreatedMsg{branch: "feature", path: "/src/repo-feature"})
+	m = newModel.(model)
+
+	newModel, _ = m.Update(worktreesMsg{
+		{Path: "/src/repo", Branch: "main"},
+		{Path: "/src/repo-bugfix", Branch: "bugfix"},
+		{Path: "/src/repo-feature", Branch: "feature"},
+	})
+	m = newModel.(model)
+	if m.view != "worktrees" || m.worktrees[m.cursor].Path != "/src/repo-feature" {
+		t.Errorf("Expected cursor on the new worktree, got %d", m.cursor)
+	}
+	if m.focusWorktree != "" {
+		t.Error("Expected focus to be cleared once applied")
+	}
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fullCheckout is the profile name that stands for no sparse checkout.
+const fullCheckout = "full"
+
+// SparseConfig lists the sparse-checkout profiles of a repository, for
+// monorepos where checking out everything is slow.
+type SparseConfig struct {
+	Default  string              `json:"default"`  // profile new worktrees use; empty for full checkouts
+	Profiles map[string][]string `json:"profiles"` // profile name to the directories of its cone
+}
+
+// SparseProfile is a resolved profile. The zero value is a full checkout.
+type SparseProfile struct {
+	Name string
+	Dirs []string
+}
+
+func (c SparseConfig) validate() error {
+	for name, dirs := range c.Profiles {
+		if name == fullCheckout {
+			return fmt.Errorf("sparse profile name %q is reserved for full checkouts", fullCheckout)
+		}
+		if len(dirs) == 0 {
+			return fmt.Errorf("sparse profile %q has no directories", name)
+		}
+		for _, dir := range dirs {
+			if filepath.IsAbs(dir) || strings.HasPrefix(filepath.Clean(dir), "..") {
+				return fmt.Errorf("sparse profile %q: %q must be relative to the repository root", name, dir)
+			}
+		}
+	}
+	if c.Default != "" {
+		if _, err := c.profile(c.Default); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// profile resolves a profile by name; "" and "full" mean a full checkout.
+func (c SparseConfig) profile(name string) (SparseProfile, error) {
+	if name == "" || name == fullCheckout {
+		return SparseProfile{}, nil
+	}
+	dirs, ok := c.Profiles[name]
+	if !ok {
+		return SparseProfile{}, fmt.Errorf("unknown sparse profile '%s'", name)
+	}
+	return SparseProfile{Name: name, Dirs: dirs}, nil
+}
+
+// names lists "full" followed by the profiles in alphabetical order.
+func (c SparseConfig) names() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append([]string{fullCheckout}, names...)
+}
+
+// loadSparseConfig resolves the sparse profiles of repo.
+func loadSparseConfig(repo string, cfg Config) (SparseConfig, error) {
+	if len(cfg.Sparse) == 0 {
+		return SparseConfig{}, nil
+	}
+	repoRoot, err := getRepoRoot(repo)
+	if err != nil {
+		return SparseConfig{}, err
+	}
+	return repoEntry(cfg.Sparse, repoRoot), nil
+}
+
+// setSparseProfile narrows the worktree at path to the cone of profile, or
+// restores a full checkout for the zero profile, and remembers the choice.
+func setSparseProfile(path string, profile SparseProfile) error {
+	var err error
+	if profile.Name == "" {
+		_, err = gitIn(path, "sparse-checkout", "disable")
+	} else {
+		_, err = gitIn(path, append([]string{"sparse-checkout", "set", "--cone"}, profile.Dirs...)...)
+	}
+	if err != nil {
+		return err
+	}
+	return editWorktreeMeta(path, path, func(meta *WorktreeMeta) {
+		meta.Sparse = profile.Name
+	})
+}
+
+type sparseProfileSetMsg struct {
+	path    string
+	profile string
+}
+
+func setSparseProfileCmd(path string, profile SparseProfile) tea.Cmd {
+	return func() tea.Msg {
+		if err := setSparseProfile(path, profile); err != nil {
+			return err
+		}
+		return sparseProfileSetMsg{path: path, profile: profile.Name}
+	}
+}
+
+// defaultSparse is the configured default profile of repo.
+func defaultSparse(repo string, cfg Config) string {
+	sparse, err := loadSparseConfig(repo, cfg)
+	if err != nil {
+		return ""
+	}
+	return sparse.Default
+}
+
+// startSparsePicker picks the sparse profile of the worktree at path, or
+// the one new worktrees use when path is empty.
+func (m *model) startSparsePicker(path string) tea.Cmd {
+	sparse, err := loadSparseConfig(m.repo, m.config)
+	if err != nil || len(sparse.Profiles) == 0 {
+		m.statusMessage = markers.Error + " No sparse profiles configured for this repository"
+		return clearStatusAfterDelay()
+	}
+
+	current := m.newSparse
+	if path != "" {
+		for _, worktree := range m.allWorktrees {
+			if worktree.Path == path {
+				current = worktree.Meta.Sparse
+			}
+		}
+	}
+
+	m.choosingSparse = true
+	m.sparseFor = path
+	m.sparseNames = sparse.names()
+	m.sparseChoice = 0
+	for i, name := range m.sparseNames {
+		if name == current {
+			m.sparseChoice = i
+		}
+	}
+	return nil
+}
+
+func (m *model) cancelSparsePicker() {
+	m.choosingSparse = false
+	m.sparseFor = ""
+	m.sparseNames = nil
+}
+
+func (m *model) cycleSparseChoice(delta int) {
+	count := len(m.sparseNames)
+	m.sparseChoice = (m.sparseChoice + delta + count) % count
+}
+
+func (m model) confirmSparsePicker() (model, tea.Cmd) {
+	name := m.sparseNames[m.sparseChoice]
+	path := m.sparseFor
+	m.cancelSparsePicker()
+
+	if path == "" {
+		m.newSparse = name
+		if name == fullCheckout {
+			m.newSparse = ""
+		}
+		m.statusMessage = fmt.Sprintf("%s New worktrees use the %s checkout", markers.OK, describeSparse(m.newSparse))
+		return m, clearStatusAfterDelay()
+	}
+
+	sparse, err := loadSparseConfig(m.repo, m.config)
+	if err != nil {
+		return m, func() tea.Msg { return err }
+	}
+	profile, err := sparse.profile(name)
+	if err != nil {
+		return m, func() tea.Msg { return err }
+	}
+	m.statusMessage = fmt.Sprintf("Switching %s to the %s checkout...", filepath.Base(path), describeSparse(profile.Name))
+	return m, setSparseProfileCmd(path, profile)
+}
+
+// describeSparse names a profile for status lines: "full" or "sparse 'web'".
+func describeSparse(name string) string {
+	if name == "" {
+		return fullCheckout
+	}
+	return fmt.Sprintf("sparse '%s'", name)
+}
+
+func (m model) renderSparsePicker() string {
+	label := "Sparse profile for new worktrees: "
+	if m.sparseFor != "" {
+		label = fmt.Sprintf("Sparse profile for %s: ", filepath.Base(m.sparseFor))
+	}
+	parts := make([]string, len(m.sparseNames))
+	for i, name := range m.sparseNames {
+		if i == m.sparseChoice {
+			parts[i] = selectedTextStyle.Render(name)
+		} else {
+			parts[i] = pathStyle.Render(name)
+		}
+	}
+	return inputStyle.Render(label) + strings.Join(parts, " ")
+}
+
+// runSparseCommand shows or changes the sparse profile of the current
+// worktree.
+func runSparseCommand(cfg Config, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("usage: wtree sparse [profile|full]")
+	}
+	sparse, err := loadSparseConfig(".", cfg)
+	if err != nil {
+		return err
+	}
+	path, err := getRepoRoot(".")
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		metadata, err := loadMetadata(".")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Checkout: %s\n", describeSparse(metadata[path].Sparse))
+		fmt.Printf("Profiles: %s\n", strings.Join(sparse.names(), ", "))
+		return nil
+	}
+
+	profile, err := sparse.profile(args[0])
+	if err != nil {
+		return err
+	}
+	if err := setSparseProfile(path, profile); err != nil {
+		return err
+	}
+	fmt.Printf("Switched '%s' to the %s checkout\n", path, describeSparse(profile.Name))
+	return nil
+}
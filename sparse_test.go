@@ -0,0 +1,126 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSparseConfigValidate(t *testing.T) {
+	valid := SparseConfig{Default: "web", Profiles: map[string][]string{"web": {"apps/web", "libs/ui"}}}
+	if err := valid.validate(); err != nil {
+		t.Errorf("Expected %+v to be valid, got %v", valid, err)
+	}
+
+	invalid := []SparseConfig{
+		{Default: "api", Profiles: map[string][]string{"web": {"apps/web"}}},
+		{Profiles: map[string][]string{"full": {"apps"}}},
+		{Profiles: map[string][]string{"empty": {}}},
+		{Profiles: map[string][]string{"outside": {"../elsewhere"}}},
+		{Profiles: map[string][]string{"absolute": {"/apps"}}},
+	}
+	for _, cfg := range invalid {
+		if err := cfg.validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", cfg)
+		}
+	}
+
+	if names := valid.names(); !reflect.DeepEqual(names, []string{"full", "web"}) {
+		t.Errorf("Expected full to come first, got %v", names)
+	}
+	if profile, err := valid.profile("full"); err != nil || profile.Name != "" {
+		t.Errorf("Expected full to be the zero profile, got %+v, %v", profile, err)
+	}
+}
+
+func TestSparseWorktree(t *testing.T) {
+	repo := newTestRepo(t)
+	t.Chdir(repo)
+
+	for _, dir := range []string{"apps/web", "apps/api"} {
+		if err := os.MkdirAll(filepath.Join(repo, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, filepath.Join(repo, dir, "main.go"), "package main\n")
+	}
+	runGit(t, repo, "add", ".")
+	runGit(t, repo, "commit", "-m", "apps")
+	runGit(t, repo, "branch", "feature/sparse")
+
+	web := SparseProfile{Name: "web", Dirs: []string{"apps/web"}}
+	path, err := createWorktree(".", Branch{Name: "feature/sparse", Type: "local"}, createOptions{sparse: web})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(path, name))
+		return err == nil
+	}
+	if !exists("apps/web/main.go") || !exists("README") {
+		t.Error("Expected the cone and the top-level files to be checked out")
+	}
+	if exists("apps/api") {
+		t.Error("Expected apps/api to be left out")
+	}
+	if status := runGit(t, path, "status", "--porcelain"); status != "" {
+		t.Errorf("Expected a clean sparse worktree, got %q", status)
+	}
+	metadata, err := loadMetadata(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata[path].Sparse != "web" {
+		t.Errorf("Expected the profile to be recorded, got %q", metadata[path].Sparse)
+	}
+
+	if err := setSparseProfile(path, SparseProfile{}); err != nil {
+		t.Fatal(err)
+	}
+	if !exists("apps/api/main.go") {
+		t.Error("Expected a full checkout after switching to full")
+	}
+	metadata, err = loadMetadata(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata[path].Sparse != "" {
+		t.Errorf("Expected the profile to be cleared, got %q", metadata[path].Sparse)
+	}
+}
+
+func TestSparsePicker(t *testing.T) {
+	m := initialModel()
+	m.config.Sparse = map[string]SparseConfig{"*": {Profiles: map[string][]string{"api": {"apps/api"}, "web": {"apps/web"}}}}
+	m.newSparse = "web"
+
+	m.startSparsePicker("")
+	if !m.choosingSparse || m.sparseNames[m.sparseChoice] != "web" {
+		t.Fatalf("Expected the picker on the current profile, got %v at %d", m.sparseNames, m.sparseChoice)
+	}
+	m.cycleSparseChoice(1)
+	if m.sparseNames[m.sparseChoice] != "full" {
+		t.Errorf("Expected cycling to wrap to full, got %q", m.sparseNames[m.sparseChoice])
+	}
+
+	m, _ = m.confirmSparsePicker()
+	if m.choosingSparse || m.newSparse != "" {
+		t.Errorf("Expected new worktrees to use full checkouts, got %q", m.newSparse)
+	}
+}
+
+func TestUnknownSparseProfileStopsCreation(t *testing.T) {
+	m := initialModel()
+	m.config.Sparse = map[string]SparseConfig{"*": {Profiles: map[string][]string{"web": {"apps/web"}}}}
+	m.newSparse = "gone"
+
+	m, cmd := m.startScratch()
+	if m.creatingWorktree || cmd == nil {
+		t.Fatalf("Expected no worktree to be created, got creating %v", m.creatingWorktree)
+	}
+	if !strings.Contains(m.statusMessage, "unknown sparse profile 'gone'") {
+		t.Errorf("Expected the profile error in the status line, got %q", m.statusMessage)
+	}
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sahilm/fuzzy"
+)
+
+// Stash is an entry of `git stash list`. Stashes live in the common git
+// dir, so every worktree sees the same list.
+type Stash struct {
+	Ref     string // stash@{n}, which shifts as stashes come and go
+	Commit  string
+	Branch  string // branch the stash was made on, "(no branch)" if detached
+	Message string
+	Created time.Time
+}
+
+// parseStashSubject splits a stash reflog subject such as
+// "WIP on main: 1a2b3c4 fix" or "On main: message" into branch and message.
+func parseStashSubject(subject string) (string, string) {
+	rest, ok := strings.CutPrefix(subject, "WIP on ")
+	if !ok {
+		rest, ok = strings.CutPrefix(subject, "On ")
+	}
+	if !ok {
+		return "", subject
+	}
+	branch, message, found := strings.Cut(rest, ": ")
+	if !found {
+		return "", subject
+	}
+	return branch, message
+}
+
+func getStashes(repo string) ([]Stash, error) {
+	output, err := exec.Command("git", "-C", repo, "stash", "list", "--format=%H|%gd|%ct|%gs").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var stashes []Stash
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		parts := strings.SplitN(line, "|", 4)
+		if len(parts) != 4 {
+			continue
+		}
+		created, _ := strconv.ParseInt(parts[2], 10, 64)
+		branch, message := parseStashSubject(parts[3])
+		stashes = append(stashes, Stash{
+			Ref:     parts[1],
+			Commit:  parts[0],
+			Branch:  branch,
+			Message: message,
+			Created: time.Unix(created, 0),
+		})
+	}
+	return stashes, nil
+}
+
+// stashStat is the diffstat of a stash, untracked files included where
+// git supports showing them.
+func stashStat(repo, commit string) string {
+	output, err := exec.Command("git", "-C", repo, "stash", "show", "--stat", "--include-untracked", commit).Output()
+	if err != nil {
+		output, _ = exec.Command("git", "-C", repo, "stash", "show", "--stat", commit).Output()
+	}
+	return strings.TrimRight(string(output), "\n")
+}
+
+// applyStashTo applies the stash commit in the worktree at dir, restoring
+// the index when it can, and drops it afterwards when pop is set. A stash
+// that conflicts is kept and the conflicting files are reported.
+func applyStashTo(dir, commit string, pop bool) error {
+	_, err := gitIn(dir, "stash", "apply", "--index", "--quiet", commit)
+	if err != nil && len(conflictedFiles(dir)) == 0 {
+		// The index may not apply here; try the changes alone, which git
+		// refuses up front when they would overwrite local changes
+		_, err = gitIn(dir, "stash", "apply", "--quiet", commit)
+	}
+	if err != nil {
+		if conflicts := conflictedFiles(dir); len(conflicts) > 0 {
+			return fmt.Errorf("stash applied with conflicts in %s and was kept", strings.Join(conflicts, ", "))
+		}
+		return err
+	}
+	if pop {
+		return dropStash(dir, commit)
+	}
+	return nil
+}
+
+type stashesMsg []Stash
+
+type stashAppliedMsg struct {
+	ref  string
+	path string
+	pop  bool
+}
+
+type stashDroppedMsg struct {
+	ref    string
+	commit string
+}
+
+type stashStatMsg struct {
+	commit string
+	stat   string
+}
+
+// loadSelectedStashStat starts computing the diffstat of the selected
+// stash unless it is cached. Only the selected stash shows one, and a
+// stash never changes, so each is computed at most once.
+func (m *model) loadSelectedStashStat() tea.Cmd {
+	stash, ok := m.selectedStash()
+	if !ok {
+		return nil
+	}
+	repo := m.repo
+	return m.stashStats.load(stash.Commit, inRepo(repo, func() tea.Msg {
+		return stashStatMsg{commit: stash.Commit, stat: stashStat(repo, stash.Commit)}
+	}))
+}
+
+func getStashesCmd(repo string) tea.Cmd {
+	return inRepo(repo, func() tea.Msg {
+		stashes, err := getStashes(repo)
+		if err != nil {
+			return stashesMsg{}
+		}
+		return stashesMsg(stashes)
+	})
+}
+
+func applyStashCmd(stash Stash, path string, pop bool) tea.Cmd {
+	return func() tea.Msg {
+		if err := applyStashTo(path, stash.Commit, pop); err != nil {
+			return err
+		}
+		return stashAppliedMsg{ref: stash.Ref, path: path, pop: pop}
+	}
+}
+
+// startDropStash asks for confirmation before dropping stash.
+func (m *model) startDropStash(stash Stash) {
+	m.confirmingDrop = true
+	m.stashSource = stash
+}
+
+func (m *model) cancelDropStash() {
+	m.confirmingDrop = false
+	m.stashSource = Stash{}
+}
+
+func (m model) confirmDropStash() (model, tea.Cmd) {
+	stash := m.stashSource
+	m.cancelDropStash()
+	m.statusMessage = fmt.Sprintf("Dropping %s...", stash.Ref)
+	return m, dropStashCmd(m.repo, stash)
+}
+
+func (m model) renderDropConfirm() string {
+	stash := m.stashSource
+	prompt := fmt.Sprintf("Drop %s (%s)?", stash.Ref, stash.Message)
+	return errorStyle.UnsetPaddingLeft().Render(prompt) + pathStyle.Render(" git stash apply "+stash.Commit[:7]+" recovers it until git gc")
+}
+
+func dropStashCmd(repo string, stash Stash) tea.Cmd {
+	return func() tea.Msg {
+		if err := dropStash(repo, stash.Commit); err != nil {
+			return err
+		}
+		return stashDroppedMsg{ref: stash.Ref, commit: stash.Commit}
+	}
+}
+
+func (m *model) filterStashes() {
+	selected := m.selectionID("stashes")
+	defer func() { m.restoreSelection("stashes", selected) }()
+
+	query := strings.TrimSpace(m.filterInput.Value())
+	if query == "" {
+		m.stashes = m.allStashes
+		return
+	}
+
+	searchText := make([]string, len(m.allStashes))
+	for i, stash := range m.allStashes {
+		searchText[i] = stash.Branch + " " + stash.Message
+	}
+	matches := fuzzy.Find(query, searchText)
+	m.stashes = make([]Stash, 0, len(matches))
+	for _, match := range matches {
+		m.stashes = append(m.stashes, m.allStashes[match.Index])
+	}
+}
+
+// stashOrigin is the worktree that has the stash's branch checked out.
+func (m model) stashOrigin(stash Stash) (Worktree, bool) {
+	if stash.Branch == "" {
+		return Worktree{}, false
+	}
+	for _, worktree := range m.allWorktrees {
+		if worktree.Branch == stash.Branch {
+			return worktree, true
+		}
+	}
+	return Worktree{}, false
+}
+
+// startApplyStash asks which worktree to apply (or pop) the stash to,
+// proposing the worktree it came from.
+func (m *model) startApplyStash(stash Stash, pop bool) tea.Cmd {
+	m.applyingStash = true
+	m.stashPop = pop
+	m.stashSource = stash
+	m.stashTarget = -1
+	m.stashInput.SetValue("")
+	if origin, ok := m.stashOrigin(stash); ok {
+		m.stashInput.SetValue(filepath.Base(origin.Path))
+		m.stashInput.CursorEnd()
+	}
+	return m.stashInput.Focus()
+}
+
+func (m *model) cancelApplyStash() {
+	m.applyingStash = false
+	m.stashSource = Stash{}
+	m.stashInput.SetValue("")
+	m.stashInput.Blur()
+}
+
+func (m *model) cycleStashTarget(delta int) {
+	index, target, ok := m.cycleWorktree("", m.stashTarget, delta)
+	if !ok {
+		return
+	}
+	m.stashTarget = index
+	m.stashInput.SetValue(filepath.Base(target.Path))
+	m.stashInput.CursorEnd()
+}
+
+func (m model) confirmApplyStash() (model, tea.Cmd) {
+	name := strings.TrimSpace(m.stashInput.Value())
+	if name == "" {
+		return m, nil
+	}
+	target, ok := findWorktree(m.allWorktrees, name)
+	if !ok {
+		m.statusMessage = fmt.Sprintf("%s No worktree named '%s'", markers.Error, name)
+		return m, clearStatusAfterDelay()
+	}
+	stash, pop := m.stashSource, m.stashPop
+	m.cancelApplyStash()
+	m.statusMessage = fmt.Sprintf("Applying %s to %s...", stash.Ref, filepath.Base(target.Path))
+	return m, applyStashCmd(stash, target.Path, pop)
+}
+
+func (m model) renderApplyStashInput() string {
+	verb := "Apply"
+	if m.stashPop {
+		verb = "Pop"
+	}
+	return inputStyle.Render(fmt.Sprintf("%s %s to: ", verb, m.stashSource.Ref)) + m.stashInput.View()
+}
+
+// stashListHeight is how many stashes fit above the preview of the
+// selected one.
+func (m model) stashListHeight() int {
+	if m.viewportHeight < 4 {
+		return 1
+	}
+	return m.viewportHeight / 2
+}
+
+func (m model) renderStashItem(stash Stash, selected bool) string {
+	textStyle := lipgloss.NewStyle()
+	if selected {
+		textStyle = selectedTextStyle
+	}
+
+	branch := stash.Branch
+	if origin, ok := m.stashOrigin(stash); ok {
+		branch += " · " + filepath.Base(origin.Path)
+	}
+	content := fmt.Sprintf("%s %s %s %s",
+		tagTypeStyle.Render(stash.Ref),
+		branchTypeStyle.Render("["+branch+"]"),
+		textStyle.Render(stash.Message),
+		pathStyle.Render(humanDuration(time.Since(stash.Created))+" ago"))
+
+	if selected {
+		return selectedItemStyle.Render(markers.Cursor + " " + content)
+	}
+	return normalItemStyle.Render("  " + content)
+}
+
+// renderStashPreview shows the diffstat of the selected stash in the lines
+// left below the list.
+func (m model) renderStashPreview(stash Stash) string {
+	stat, ok := m.stashStats.get(stash.Commit)
+	if !ok {
+		return pathStyle.Render("    Loading diffstat...") + "\n"
+	}
+	lines := strings.Split(stat, "\n")
+	room := m.viewportHeight - m.stashListHeight() - 1
+	if room < 1 {
+		room = 1
+	}
+	if len(lines) > room {
+		summary := lines[len(lines)-1]
+		lines = append(lines[:room-1], summary)
+	}
+	var content strings.Builder
+	for _, line := range lines {
+		content.WriteString(pathStyle.Render("    " + line))
+		content.WriteString("\n")
+	}
+	return content.String()
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestParseStashSubject(t *testing.T) {
+	tests := []struct {
+		subject string
+		branch  string
+		message string
+	}{
+		{"WIP on main: 1a2b3c4 fix typo", "main", "1a2b3c4 fix typo"},
+		{"On feature/x: half done", "feature/x", "half done"},
+		{"On (no branch): detached work", "(no branch)", "detached work"},
+		{"autostash", "", "autostash"},
+	}
+	for _, tt := range tests {
+		branch, message := parseStashSubject(tt.subject)
+		if branch != tt.branch || message != tt.message {
+			t.Errorf("parseStashSubject(%q) = %q, %q, want %q, %q", tt.subject, branch, message, tt.branch, tt.message)
+		}
+	}
+}
+
+func TestApplyStashToOtherWorktree(t *testing.T) {
+	repo := newTestRepo(t)
+	t.Chdir(repo)
+
+	target, err := createNewBranchWorktree(".", "feature/target", createOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, filepath.Join(repo, "README"), "hello\nstashed\n")
+	runGit(t, repo, "stash", "push", "-m", "half done")
+
+	stashes, err := getStashes(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stashes) != 1 {
+		t.Fatalf("Expected one stash, got %d", len(stashes))
+	}
+	stash := stashes[0]
+	if stash.Ref != "stash@{0}" || stash.Branch != "main" || stash.Message != "half done" {
+		t.Errorf("Unexpected stash %+v", stash)
+	}
+	if stat := stashStat(".", stash.Commit); !strings.Contains(stat, "README") {
+		t.Errorf("Expected the diffstat to mention README, got %q", stat)
+	}
+
+	if err := applyStashTo(target, stash.Commit, false); err != nil {
+		t.Fatal(err)
+	}
+	if content := readFile(t, filepath.Join(target, "README")); content != "hello\nstashed\n" {
+		t.Errorf("Expected the stash applied to the target, got %q", content)
+	}
+	if list := runGit(t, repo, "stash", "list"); list == "" {
+		t.Error("Expected apply to keep the stash")
+	}
+
+	runGit(t, target, "checkout", "--", "README")
+	if err := applyStashTo(target, stash.Commit, true); err != nil {
+		t.Fatal(err)
+	}
+	if list := runGit(t, repo, "stash", "list"); list != "" {
+		t.Errorf("Expected pop to drop the stash, got %q", list)
+	}
+}
+
+func TestApplyStashConflictKeepsStash(t *testing.T) {
+	repo := newTestRepo(t)
+	t.Chdir(repo)
+
+	target, err := createNewBranchWorktree(".", "feature/diverged", createOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(target, "README"), "goodbye\n")
+	runGit(t, target, "commit", "-am", "diverge")
+
+	writeFile(t, filepath.Join(repo, "README"), "hello again\n")
+	runGit(t, repo, "stash")
+	commit := runGit(t, repo, "rev-parse", "stash@{0}")
+
+	err = applyStashTo(target, commit, true)
+	if err == nil || !strings.Contains(err.Error(), "README") {
+		t.Fatalf("Expected a conflict in README, got %v", err)
+	}
+	if list := runGit(t, repo, "stash", "list"); list == "" {
+		t.Error("Expected a conflicting pop to keep the stash")
+	}
+}
+
+func TestStartApplyStashProposesOrigin(t *testing.T) {
+	m := initialModel()
+	m.allWorktrees = []Worktree{
+		{Path: "/src/repo", Branch: "main"},
+		{Path: "/src/repo-feature", Branch: "feature"},
+	}
+
+	m.startApplyStash(Stash{Ref: "stash@{0}", Branch: "feature"}, true)
+	if !m.applyingStash || !m.stashPop || m.stashInput.Value() != "repo-feature" {
+		t.Errorf("Expected the origin worktree proposed, got applying=%v pop=%v input=%q",
+			m.applyingStash, m.stashPop, m.stashInput.Value())
+	}
+
+	m.cycleStashTarget(1)
+	if m.stashInput.Value() != "repo" {
+		t.Errorf("Expected cycling to start at the first worktree, got %q", m.stashInput.Value())
+	}
+
+	m.cancelApplyStash()
+	if m.applyingStash || m.stashInput.Value() != "" {
+		t.Error("Expected cancel to close the input")
+	}
+}
+
+func TestDropStashAsksForConfirmation(t *testing.T) {
+	m := initialModel()
+	m.view = "stashes"
+	stash := Stash{Ref: "stash@{0}", Commit: "0123456789abcdef", Message: "half done"}
+	m.allStashes = []Stash{stash}
+	m.stashes = m.allStashes
+	m.stashStats.set(stash.Commit, "")
+
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	m = newModel.(model)
+	if !m.confirmingDrop || cmd != nil {
+		t.Fatalf("Expected 'd' to ask for confirmation without dropping, got confirming=%v", m.confirmingDrop)
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = newModel.(model)
+	if m.confirmingDrop {
+		t.Error("Expected esc to cancel the drop")
+	}
+
+	m.startDropStash(stash)
+	newModel, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(model)
+	if m.confirmingDrop || cmd == nil {
+		t.Error("Expected enter to drop the stash")
+	}
+}
+
+func TestStashStatLoadsForSelectedStashOnly(t *testing.T) {
+	m := initialModel()
+	m.view = "stashes"
+
+	newModel, cmd := m.Update(stashesMsg{
+		{Ref: "stash@{0}", Commit: "aaaa"},
+		{Ref: "stash@{1}", Commit: "bbbb"},
+	})
+	m = newModel.(model)
+	if cmd == nil {
+		t.Fatal("Expected the diffstat of the selected stash to load")
+	}
+	if _, ok := m.stashStats.entries["aaaa"]; !ok {
+		t.Error("Expected the selected stash to be loading")
+	}
+	if _, ok := m.stashStats.entries["bbbb"]; ok {
+		t.Error("Expected other stashes to be left alone")
+	}
+	if preview := m.renderStashPreview(m.stashes[0]); !strings.Contains(preview, "Loading") {
+		t.Errorf("Expected a loading preview, got %q", preview)
+	}
+
+	newModel, _ = m.Update(stashStatMsg{commit: "aaaa", stat: " README | 1 +"})
+	m = newModel.(model)
+	if preview := m.renderStashPreview(m.stashes[0]); !strings.Contains(preview, "README") {
+		t.Errorf("Expected the loaded diffstat, got %q", preview)
+	}
+	if cmd := m.loadSelectedStashStat(); cmd != nil {
+		t.Error("Expected a cached diffstat not to load again")
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+type subcommand struct {
+	usage   string
+	summary string // shown next to the usage in --help
+	run     func(cfg Config, args []string) error
+}
+
+// subcommands are invoked as `wtree <name> [args...]`.
+var subcommands = map[string]subcommand{
+	"pr":      {usage: "wtree pr <number>", summary: "Create a worktree for a GitHub PR / GitLab MR", run: runPRCommand},
+	"scratch": {usage: "wtree scratch", summary: "Create a scratch worktree that expires", run: runScratchCommand},
+	"gc":      {usage: "wtree gc [--dry-run]", summary: "Remove expired scratch worktrees without unsaved work", run: runGCCommand},
+	"move":    {usage: "wtree move [-u] [-b] <worktree or new branch>", summary: "Move uncommitted changes to another worktree", run: runMoveCommand},
+	"promote": {usage: "wtree promote <new branch>", summary: "Move uncommitted changes to a new branch off HEAD", run: runPromoteCommand},
+	"recent":  {usage: "wtree recent [-n count] [position]", summary: "List or print recently opened worktrees", run: runRecentCommand},
+	"sparse":  {usage: "wtree sparse [profile|full]", summary: "Show or change the sparse profile of this worktree", run: runSparseCommand},
+	"lfs":     {usage: "wtree lfs [pull [pattern...]]", summary: "Show or pull the LFS pointer files of this worktree", run: runLFSCommand},
+	"du":      {usage: "wtree du", summary: "Show the disk usage of every worktree", run: runDUCommand},
+}
+
+func runSubcommand(cfg Config, args []string) {
+	sub, ok := subcommands[args[0]]
+	if !ok {
+		fmt.Printf("Error: unknown command '%s'\n", args[0])
+		fmt.Println("Available commands:")
+		for _, usage := range subcommandUsages() {
+			fmt.Printf("  %s\n", usage)
+		}
+		os.Exit(1)
+	}
+	if err := sub.run(cfg, args[1:]); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// subcommandUsages lists every subcommand with its summary, sorted, for
+// --help and the unknown command error.
+func subcommandUsages() []string {
+	var usages []string
+	for _, sub := range subcommands {
+		usages = append(usages, fmt.Sprintf("%-27s %s", sub.usage, sub.summary))
+	}
+	sort.Strings(usages)
+	return usages
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// submoduleStatusMaxAge is how long the submodule status of a worktree is
+// shown before a refresh checks it again, since work inside a submodule
+// changes it without touching the repository itself.
+const submoduleStatusMaxAge = 30 * time.Second
+
+// SubmoduleConfig controls what happens to submodules in new worktrees.
+type SubmoduleConfig struct {
+	// Init runs `git submodule update --init --recursive` after creation.
+	Init bool `json:"init"`
+	// Reference copies objects from the submodules of the main checkout
+	// instead of downloading them again.
+	Reference bool `json:"reference"`
+}
+
+// SubmoduleStatus counts the submodules of a worktree that need attention.
+type SubmoduleStatus struct {
+	Total         int
+	Uninitialized int
+	Modified      int // checked out at another commit, or with changes inside
+}
+
+// loadSubmoduleConfig resolves the submodule settings of repo.
+func loadSubmoduleConfig(repo string, cfg Config) (SubmoduleConfig, error) {
+	if len(cfg.Submodules) == 0 {
+		return SubmoduleConfig{}, nil
+	}
+	repoRoot, err := getRepoRoot(repo)
+	if err != nil {
+		return SubmoduleConfig{}, err
+	}
+	return repoEntry(cfg.Submodules, repoRoot), nil
+}
+
+func hasSubmodules(path string) bool {
+	_, err := os.Stat(filepath.Join(path, ".gitmodules"))
+	return err == nil
+}
+
+// submodulePaths lists the top-level submodules declared in the worktree
+// at path.
+func submodulePaths(path string) ([]string, error) {
+	output, err := gitIn(path, "config", "--file", ".gitmodules", "--get-regexp", `^submodule\..*\.path$`)
+	if err != nil {
+		// No matching entries is not an error
+		if output == "" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var paths []string
+	for _, line := range strings.Split(output, "\n") {
+		if _, subpath, ok := strings.Cut(line, " "); ok {
+			paths = append(paths, subpath)
+		}
+	}
+	return paths, nil
+}
+
+// mainCheckout returns the main worktree of the repository the worktree at
+// path belongs to, which `git worktree list` always lists first. It is ""
+// for a bare repository, which has no checkout.
+func mainCheckout(path string) (string, error) {
+	output, err := gitIn(path, "worktree", "list", "--porcelain")
+	if err != nil {
+		return "", err
+	}
+	first, _, _ := strings.Cut(output, "\n\n")
+	var main string
+	for _, line := range strings.Split(first, "\n") {
+		if line == "bare" {
+			return "", nil
+		}
+		if worktree, ok := strings.CutPrefix(line, "worktree "); ok {
+			main = worktree
+		}
+	}
+	return main, nil
+}
+
+// initSubmodules checks out the submodules of the new worktree at path, if
+// the config asks for it. With Reference each submodule copies the objects
+// of its checkout in the main worktree, when that one is initialized. The
+// copy is dissociated, so removing the main checkout's submodules later
+// doesn't break the new worktree.
+func initSubmodules(cfg SubmoduleConfig, path string) error {
+	if !cfg.Init || !hasSubmodules(path) {
+		return nil
+	}
+	if !cfg.Reference {
+		_, err := gitIn(path, "submodule", "update", "--init", "--recursive")
+		return err
+	}
+
+	main, err := mainCheckout(path)
+	if err != nil {
+		return err
+	}
+	subpaths, err := submodulePaths(path)
+	if err != nil {
+		return err
+	}
+	for _, subpath := range subpaths {
+		args := []string{"submodule", "update", "--init", "--recursive"}
+		reference := filepath.Join(main, subpath)
+		if _, err := os.Stat(filepath.Join(reference, ".git")); err == nil && main != "" && reference != filepath.Join(path, subpath) {
+			args = append(args, "--reference", reference, "--dissociate")
+		}
+		if _, err := gitIn(path, append(args, "--", subpath)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getSubmoduleStatus reports the submodules of the worktree at path. It
+// returns the zero status for worktrees without submodules.
+func getSubmoduleStatus(path string) SubmoduleStatus {
+	var status SubmoduleStatus
+	if !hasSubmodules(path) {
+		return status
+	}
+
+	output, err := gitIn(path, "submodule", "status", "--recursive")
+	if err != nil || output == "" {
+		return status
+	}
+	modified := modifiedSubmodules(path)
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+		status.Total++
+		fields := strings.Fields(line[1:])
+		switch {
+		case line[0] == '-':
+			status.Uninitialized++
+		case line[0] == '+' || line[0] == 'U':
+			status.Modified++
+		case len(fields) > 1 && modified[fields[1]]:
+			status.Modified++
+		}
+	}
+	return status
+}
+
+// modifiedSubmodules lists the top-level submodules with changes inside,
+// from the submodule field of `git status --porcelain=2`.
+func modifiedSubmodules(path string) map[string]bool {
+	modified := make(map[string]bool)
+	output, err := gitIn(path, "status", "--porcelain=2", "--ignore-submodules=none")
+	if err != nil {
+		return modified
+	}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 9 || fields[0] != "1" || !strings.HasPrefix(fields[2], "S") {
+			continue
+		}
+		if strings.ContainsAny(fields[2][1:], "CMU") {
+			modified[fields[8]] = true
+		}
+	}
+	return modified
+}
+
+// String summarizes the status for the worktree details, e.g.
+// "submodules: 1 uninitialized, 2 modified". It is empty when there is
+// nothing to report.
+func (s SubmoduleStatus) String() string {
+	var parts []string
+	if s.Uninitialized > 0 {
+		parts = append(parts, fmt.Sprintf("%d uninitialized", s.Uninitialized))
+	}
+	if s.Modified > 0 {
+		parts = append(parts, fmt.Sprintf("%d modified", s.Modified))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "submodules: " + strings.Join(parts, ", ")
+}
+
+type submoduleStatusMsg struct {
+	path   string
+	status SubmoduleStatus
+}
+
+// refreshSubmoduleStatus checks the submodules of the worktrees in the
+// background, skipping those checked recently.
+func (m *model) refreshSubmoduleStatus() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, wt := range m.allWorktrees {
+		path := wt.Path
+		cmds = append(cmds, m.submodules.load(path, inRepo(m.repo, func() tea.Msg {
+			return submoduleStatusMsg{path: path, status: getSubmoduleStatus(path)}
+		})))
+	}
+	return tea.Batch(cmds...)
+}
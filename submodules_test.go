@@ -0,0 +1,134 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newSubmoduleRepo is newTestRepo with a submodule at vendor/lib that is
+// initialized in the main checkout.
+func newSubmoduleRepo(t *testing.T) string {
+	t.Helper()
+	repo := newTestRepo(t)
+
+	// Local clones of submodules are refused by default since git 2.38.1
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "protocol.file.allow")
+	t.Setenv("GIT_CONFIG_VALUE_0", "always")
+
+	lib := filepath.Join(filepath.Dir(repo), "lib")
+	runGit(t, filepath.Dir(repo), "init", "-b", "main", lib)
+	writeFile(t, filepath.Join(lib, "lib.txt"), "lib\n")
+	runGit(t, lib, "add", "lib.txt")
+	runGit(t, lib, "commit", "-m", "lib")
+
+	runGit(t, repo, "submodule", "add", lib, "vendor/lib")
+	runGit(t, repo, "commit", "-m", "add lib")
+	runGit(t, repo, "push", "origin", "main")
+	return repo
+}
+
+func TestInitSubmodulesWithReference(t *testing.T) {
+	repo := newSubmoduleRepo(t)
+	t.Chdir(repo)
+
+	path, err := createNewBranchWorktree(".", "feature/sub", createOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := getSubmoduleStatus(path); status.Total != 1 || status.Uninitialized != 1 {
+		t.Errorf("Expected one uninitialized submodule, got %+v", status)
+	}
+
+	if err := initSubmodules(SubmoduleConfig{Init: true, Reference: true}, path); err != nil {
+		t.Fatal(err)
+	}
+	if content := readFile(t, filepath.Join(path, "vendor/lib/lib.txt")); content != "lib\n" {
+		t.Errorf("Expected the submodule checked out, got %q", content)
+	}
+	gitDir := runGit(t, filepath.Join(path, "vendor/lib"), "rev-parse", "--absolute-git-dir")
+	if _, err := os.Stat(filepath.Join(gitDir, "objects/info/alternates")); err == nil {
+		t.Error("Expected the submodule to be dissociated from the main checkout")
+	}
+
+	if status := getSubmoduleStatus(path); status.String() != "" {
+		t.Errorf("Expected nothing to report after init, got %q", status)
+	}
+	writeFile(t, filepath.Join(path, "vendor/lib/lib.txt"), "changed\n")
+	if status := getSubmoduleStatus(path); status.String() != "submodules: 1 modified" {
+		t.Errorf("Expected a modified submodule, got %q", status)
+	}
+}
+
+func TestInitSubmodulesDisabled(t *testing.T) {
+	repo := newSubmoduleRepo(t)
+	t.Chdir(repo)
+
+	path, err := createNewBranchWorktree(".", "feature/plain", createOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := initSubmodules(SubmoduleConfig{}, path); err != nil {
+		t.Fatal(err)
+	}
+	if status := getSubmoduleStatus(path); status.String() != "submodules: 1 uninitialized" {
+		t.Errorf("Expected the submodule left alone, got %q", status)
+	}
+}
+
+func TestMainCheckout(t *testing.T) {
+	repo := newTestRepo(t)
+	t.Chdir(repo)
+
+	path, err := createNewBranchWorktree(".", "feature/main", createOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if main, err := mainCheckout(path); err != nil || main != repo {
+		t.Errorf("Expected the main checkout %s, got %q (%v)", repo, main, err)
+	}
+
+	origin := filepath.Join(filepath.Dir(repo), "origin.git")
+	bareWorktree := filepath.Join(filepath.Dir(repo), "from-bare")
+	runGit(t, origin, "worktree", "add", bareWorktree, "main")
+	if main, err := mainCheckout(bareWorktree); err != nil || main != "" {
+		t.Errorf("Expected no main checkout for a bare repository, got %q (%v)", main, err)
+	}
+}
+
+func TestSubmoduleStatusIsCached(t *testing.T) {
+	m := initialModel()
+
+	newModel, cmd := m.Update(worktreesMsg{{Path: "/src/repo"}, {Path: "/src/repo-a"}})
+	m = newModel.(model)
+	if cmd == nil || len(m.submodules.entries) != 2 {
+		t.Fatalf("Expected the submodules of both worktrees to be checked, got %v", m.submodules.entries)
+	}
+
+	newModel, _ = m.Update(submoduleStatusMsg{path: "/src/repo-a", status: SubmoduleStatus{Total: 2, Modified: 1}})
+	m = newModel.(model)
+	if item := m.renderWorktreeItem(m.worktrees[1], false); !strings.Contains(item, "submodules: 1 modified") {
+		t.Errorf("Expected the submodule status in the details, got %q", item)
+	}
+	if cmd := m.refreshSubmoduleStatus(); cmd != nil {
+		t.Error("Expected a recent status not to be checked again")
+	}
+
+	// Work inside a submodule doesn't change the repository, so an old
+	// status is checked again on the next refresh
+	entry := m.submodules.entries["/src/repo-a"]
+	entry.loadedAt = time.Now().Add(-2 * submoduleStatusMaxAge)
+	m.submodules.entries["/src/repo-a"] = entry
+	if cmd := m.refreshSubmoduleStatus(); cmd == nil {
+		t.Error("Expected an old status to be checked again")
+	}
+
+	newModel, _ = m.Update(worktreeSetUpMsg{path: "/src/repo"})
+	m = newModel.(model)
+	if _, ok := m.submodules.entries["/src/repo"]; ok {
+		t.Error("Expected setting up a worktree to drop its status")
+	}
+}
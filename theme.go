@@ -0,0 +1,290 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Theme is the set of colors used by the TUI. Colors are anything lipgloss
+// accepts: hex strings or ANSI color numbers. An empty color means the
+// terminal default.
+type Theme struct {
+	Base             string `json:"base,omitempty"` // built-in theme to inherit unset colors from
+	Accent           string `json:"accent,omitempty"`
+	AccentText       string `json:"accent_text,omitempty"`
+	Muted            string `json:"muted,omitempty"`
+	Subtle           string `json:"subtle,omitempty"`
+	Success          string `json:"success,omitempty"`
+	Warning          string `json:"warning,omitempty"`
+	Error            string `json:"error,omitempty"`
+	Match            string `json:"match,omitempty"`
+	ReverseSelection bool   `json:"reverse_selection,omitempty"`
+}
+
+// Markers are the status glyphs shown in the list and status line.
+type Markers struct {
+	OK       string
+	Error    string
+	Pending  string
+	Deleting string
+	Cursor   string
+	Pinned   string
+	Marked   string
+}
+
+var builtinThemes = map[string]Theme{
+	"dark": {
+		Accent:     "#7C3AED",
+		AccentText: "#FFFFFF",
+		Muted:      "#666666",
+		Subtle:     "#6B7280",
+		Success:    "#10B981",
+		Warning:    "#F59E0B",
+		Error:      "#EF4444",
+		Match:      "#FBBF24",
+	},
+	"light": {
+		Accent:     "#6D28D9",
+		AccentText: "#FFFFFF",
+		Muted:      "#57534E",
+		Subtle:     "#6B7280",
+		Success:    "#047857",
+		Warning:    "#B45309",
+		Error:      "#B91C1C",
+		Match:      "#C2410C",
+	},
+	"high-contrast": {
+		Accent:     "11",
+		AccentText: "0",
+		Muted:      "15",
+		Subtle:     "15",
+		Success:    "10",
+		Warning:    "11",
+		Error:      "9",
+		Match:      "14",
+	},
+	// mono relies on text attributes only and is used for NO_COLOR.
+	"mono": {
+		ReverseSelection: true,
+	},
+}
+
+var emojiMarkers = Markers{
+	OK:       "✅",
+	Error:    "❌",
+	Pending:  "⏳",
+	Deleting: "🗑️ ",
+	Cursor:   "▶",
+	Pinned:   "📌",
+	Marked:   "☑️ ",
+}
+
+var asciiMarkers = Markers{
+	OK:       "[ok]",
+	Error:    "[error]",
+	Pending:  "[...]",
+	Deleting: "[x]",
+	Cursor:   ">",
+	Pinned:   "*",
+	Marked:   "[+]",
+}
+
+var (
+	markers = emojiMarkers
+
+	titleStyle            lipgloss.Style
+	activeTabStyle        lipgloss.Style
+	inactiveTabStyle      lipgloss.Style
+	selectedItemStyle     lipgloss.Style
+	selectedTextStyle     lipgloss.Style
+	normalItemStyle       lipgloss.Style
+	helpStyle             lipgloss.Style
+	inputStyle            lipgloss.Style
+	errorStyle            lipgloss.Style
+	statusStyle           lipgloss.Style
+	pendingStyle          lipgloss.Style
+	pathStyle             lipgloss.Style
+	versionStyle          lipgloss.Style
+	branchTypeStyle       lipgloss.Style
+	remoteBranchTypeStyle lipgloss.Style
+	tagTypeStyle          lipgloss.Style
+	labelStyle            lipgloss.Style
+	noteStyle             lipgloss.Style
+	matchStyle            lipgloss.Style
+)
+
+func init() {
+	applyTheme(builtinThemes["dark"])
+}
+
+// resolveTheme picks the theme named in the config, falling back to the
+// terminal background when it is "auto" or unset. User themes may shadow
+// built-in ones and inherit unset colors from their base.
+func resolveTheme(cfg Config, hasDarkBackground bool) (Theme, error) {
+	name := cfg.Theme
+	if name == "" || name == "auto" {
+		name = "light"
+		if hasDarkBackground {
+			name = "dark"
+		}
+	}
+
+	theme, ok := cfg.Themes[name]
+	if !ok {
+		builtin, ok := builtinThemes[name]
+		if !ok {
+			return Theme{}, fmt.Errorf("unknown theme %q", name)
+		}
+		return builtin, nil
+	}
+
+	if theme.Base == "" {
+		return theme, nil
+	}
+	base, ok := builtinThemes[theme.Base]
+	if !ok {
+		return Theme{}, fmt.Errorf("theme %q has unknown base %q", name, theme.Base)
+	}
+	return theme.inherit(base), nil
+}
+
+func (t Theme) inherit(base Theme) Theme {
+	fill := func(value *string, fallback string) {
+		if *value == "" {
+			*value = fallback
+		}
+	}
+	fill(&t.Accent, base.Accent)
+	fill(&t.AccentText, base.AccentText)
+	fill(&t.Muted, base.Muted)
+	fill(&t.Subtle, base.Subtle)
+	fill(&t.Success, base.Success)
+	fill(&t.Warning, base.Warning)
+	fill(&t.Error, base.Error)
+	fill(&t.Match, base.Match)
+	t.ReverseSelection = t.ReverseSelection || base.ReverseSelection
+	return t
+}
+
+// noColorRequested reports whether the NO_COLOR convention
+// (https://no-color.org) asks us to avoid colors.
+func noColorRequested() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// setupAppearance applies the theme and markers from the config and the
+// environment before the program starts.
+func setupAppearance(cfg Config, noColor, ascii bool) error {
+	if ascii || cfg.ASCII {
+		markers = asciiMarkers
+	}
+
+	if noColor || noColorRequested() {
+		lipgloss.SetColorProfile(termenv.Ascii)
+		applyTheme(builtinThemes["mono"])
+		return nil
+	}
+
+	theme, err := resolveTheme(cfg, lipgloss.HasDarkBackground())
+	if err != nil {
+		return err
+	}
+	applyTheme(theme)
+	return nil
+}
+
+// applyTheme rebuilds every package-level style from the theme colors.
+func applyTheme(t Theme) {
+	color := func(c string) lipgloss.TerminalColor {
+		if c == "" {
+			return lipgloss.NoColor{}
+		}
+		return lipgloss.Color(c)
+	}
+
+	titleStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(color(t.Accent)).
+		PaddingLeft(2)
+
+	activeTabStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(color(t.AccentText)).
+		Background(color(t.Accent)).
+		Reverse(t.ReverseSelection).
+		Padding(0, 2)
+
+	inactiveTabStyle = lipgloss.NewStyle().
+		Foreground(color(t.Muted)).
+		Padding(0, 2)
+
+	selectedTextStyle = lipgloss.NewStyle().
+		Foreground(color(t.AccentText)).
+		Background(color(t.Accent)).
+		Reverse(t.ReverseSelection).
+		Bold(true)
+
+	selectedItemStyle = selectedTextStyle.
+		PaddingLeft(1).
+		PaddingRight(1)
+
+	normalItemStyle = lipgloss.NewStyle().
+		PaddingLeft(2)
+
+	helpStyle = lipgloss.NewStyle().
+		Foreground(color(t.Muted)).
+		PaddingTop(1).
+		PaddingLeft(2)
+
+	inputStyle = lipgloss.NewStyle().
+		Foreground(color(t.Success)).
+		Bold(true).
+		PaddingLeft(2)
+
+	errorStyle = lipgloss.NewStyle().
+		Foreground(color(t.Error)).
+		Bold(true).
+		PaddingLeft(2)
+
+	statusStyle = lipgloss.NewStyle().
+		Foreground(color(t.Success)).
+		Bold(true).
+		PaddingLeft(2)
+
+	pendingStyle = lipgloss.NewStyle().
+		Foreground(color(t.Warning)).
+		Bold(true).
+		PaddingLeft(2)
+
+	pathStyle = lipgloss.NewStyle().
+		Foreground(color(t.Subtle))
+
+	versionStyle = lipgloss.NewStyle().
+		Foreground(color(t.Muted)).
+		PaddingLeft(2)
+
+	branchTypeStyle = lipgloss.NewStyle().
+		Foreground(color(t.Success))
+
+	remoteBranchTypeStyle = lipgloss.NewStyle().
+		Foreground(color(t.Warning))
+
+	tagTypeStyle = lipgloss.NewStyle().
+		Foreground(color(t.Accent))
+
+	labelStyle = lipgloss.NewStyle().
+		Foreground(color(t.Accent)).
+		Italic(true)
+
+	noteStyle = lipgloss.NewStyle().
+		Foreground(color(t.Muted)).
+		Italic(true)
+
+	matchStyle = lipgloss.NewStyle().
+		Foreground(color(t.Match)).
+		Bold(true).
+		Underline(true)
+}
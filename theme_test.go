@@ -0,0 +1,81 @@
+package main
+
+import "testing"
+
+func TestResolveTheme(t *testing.T) {
+	theme, err := resolveTheme(Config{}, true)
+	if err != nil || theme.Accent != builtinThemes["dark"].Accent {
+		t.Errorf("Expected auto theme on a dark terminal to be dark, got %+v (%v)", theme, err)
+	}
+
+	theme, err = resolveTheme(Config{Theme: "auto"}, false)
+	if err != nil || theme.Accent != builtinThemes["light"].Accent {
+		t.Errorf("Expected auto theme on a light terminal to be light, got %+v (%v)", theme, err)
+	}
+
+	theme, err = resolveTheme(Config{Theme: "high-contrast"}, true)
+	if err != nil || theme.Error != builtinThemes["high-contrast"].Error {
+		t.Errorf("Expected built-in high-contrast theme, got %+v (%v)", theme, err)
+	}
+
+	if _, err := resolveTheme(Config{Theme: "solarized"}, true); err == nil {
+		t.Error("Expected error for unknown theme")
+	}
+}
+
+func TestResolveUserTheme(t *testing.T) {
+	cfg := Config{
+		Theme: "mine",
+		Themes: map[string]Theme{
+			"mine":   {Base: "light", Accent: "#FF0000"},
+			"broken": {Base: "sepia"},
+		},
+	}
+
+	theme, err := resolveTheme(cfg, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if theme.Accent != "#FF0000" {
+		t.Errorf("Expected user accent to win, got %q", theme.Accent)
+	}
+	if theme.Error != builtinThemes["light"].Error {
+		t.Errorf("Expected unset colors to come from the base, got %q", theme.Error)
+	}
+
+	cfg.Theme = "broken"
+	if _, err := resolveTheme(cfg, true); err == nil {
+		t.Error("Expected error for unknown base theme")
+	}
+}
+
+func TestSetupAppearanceASCII(t *testing.T) {
+	defer func() {
+		markers = emojiMarkers
+		applyTheme(builtinThemes["dark"])
+	}()
+
+	if err := setupAppearance(Config{ASCII: true}, true, false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if markers != asciiMarkers {
+		t.Errorf("Expected ASCII markers, got %+v", markers)
+	}
+
+	m := initialModel()
+	m.worktrees = []Worktree{{Path: "/src/repo", Branch: "main"}}
+	m.deletingWorktree = true
+	m.deletingPath = "/src/repo"
+	if got := m.renderWorktreeItem(m.worktrees[0], true); got == "" || containsEmoji(got) {
+		t.Errorf("Expected an emoji-free deleting row, got %q", got)
+	}
+}
+
+func containsEmoji(s string) bool {
+	for _, r := range s {
+		if r > 0x2000 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// IssueTracker looks up the title of an issue so a branch name can be
+// proposed from it.
+type IssueTracker interface {
+	IssueTitle(key string) (string, error)
+}
+
+// TrackerConfig selects the issue tracker used by the new branch flow.
+type TrackerConfig struct {
+	Provider string   `json:"provider"`  // "jira", "linear" or "github"; empty disables
+	BaseURL  string   `json:"base_url"`  // API root, defaults to the public service
+	Repo     string   `json:"repo"`      // GitHub "owner/name"; detected from the remote when empty
+	Remote   string   `json:"remote"`    // remote used for detection, defaults to "origin"
+	User     string   `json:"user"`      // Jira account email, enables basic auth
+	TokenEnv string   `json:"token_env"` // environment variable holding the API token
+	Template string   `json:"template"`  // branch template, defaults to "{key}-{title}"
+	Projects []string `json:"projects"`  // Jira or Linear project keys, e.g. "ABC" for ABC-123
+}
+
+// maxSlugLength caps the slugified title so long issue titles still give
+// usable branch names.
+const maxSlugLength = 50
+
+var (
+	trackerKeyPattern  = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*-\d+$`)
+	githubIssuePattern = regexp.MustCompile(`^#?\d+$`)
+)
+
+// issueKeyEscape in front of the new branch input takes the rest as a
+// branch name, even when it looks like an issue key.
+const issueKeyEscape = `\`
+
+// issueKey reports whether input is an issue key for the configured
+// tracker, returning it normalised. Jira and Linear keys only count for
+// the configured projects, so names like hotfix-42 stay branch names.
+func (c TrackerConfig) issueKey(input string) (string, bool) {
+	input = strings.TrimSpace(input)
+	switch c.Provider {
+	case "jira", "linear":
+		if !trackerKeyPattern.MatchString(input) {
+			break
+		}
+		project, _, _ := strings.Cut(input, "-")
+		for _, p := range c.Projects {
+			if strings.EqualFold(p, project) {
+				return strings.ToUpper(input), true
+			}
+		}
+	case "github":
+		if githubIssuePattern.MatchString(input) {
+			return strings.TrimPrefix(input, "#"), true
+		}
+	}
+	return "", false
+}
+
+// branchName fills the template with the issue key and slugified title.
+func (c TrackerConfig) branchName(key, title string) string {
+	template := defaultString(c.Template, "{key}-{title}")
+	name := strings.ReplaceAll(template, "{key}", key)
+	return strings.ReplaceAll(name, "{title}", slugify(title))
+}
+
+var slugSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify lowercases s and joins its alphanumeric words with dashes,
+// cutting at a word boundary once maxSlugLength is reached.
+func slugify(s string) string {
+	slug := strings.Trim(slugSeparators.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	if len(slug) <= maxSlugLength {
+		return slug
+	}
+	slug = slug[:maxSlugLength]
+	if i := strings.LastIndex(slug, "-"); i > 0 {
+		slug = slug[:i]
+	}
+	return strings.Trim(slug, "-")
+}
+
+// newIssueTracker builds the configured tracker, or returns nil when no
+// tracker is configured.
+func newIssueTracker(dir string, cfg TrackerConfig) (IssueTracker, error) {
+	client := forgeClient{http: &http.Client{Timeout: 10 * time.Second}}
+
+	switch cfg.Provider {
+	case "":
+		return nil, nil
+	case "jira":
+		if cfg.BaseURL == "" {
+			return nil, fmt.Errorf("the jira tracker needs a base_url")
+		}
+		client.baseURL = cfg.BaseURL + "/rest/api/2"
+		client.token = os.Getenv(defaultString(cfg.TokenEnv, "JIRA_TOKEN"))
+		client.authHeader = "Authorization"
+		client.authPrefix = "Bearer "
+		if cfg.User != "" && client.token != "" {
+			// Jira Cloud API tokens use basic auth with the account email
+			client.authPrefix = "Basic "
+			client.token = base64.StdEncoding.EncodeToString([]byte(cfg.User + ":" + client.token))
+		}
+		return jiraTracker{client}, nil
+	case "linear":
+		client.baseURL = defaultString(cfg.BaseURL, "https://api.linear.app")
+		client.token = os.Getenv(defaultString(cfg.TokenEnv, "LINEAR_API_KEY"))
+		client.authHeader = "Authorization"
+		return linearTracker{client}, nil
+	case "github":
+		repo, err := detectRepo(dir, cfg.Repo, cfg.Remote)
+		if err != nil {
+			return nil, err
+		}
+		client.repo = repo
+		client.baseURL = defaultString(cfg.BaseURL, "https://api.github.com")
+		client.token = os.Getenv(defaultString(cfg.TokenEnv, "GITHUB_TOKEN"))
+		client.authHeader = "Authorization"
+		client.authPrefix = "Bearer "
+		return githubIssueTracker{client}, nil
+	}
+	return nil, fmt.Errorf("unknown issue tracker %q (expected jira, linear or github)", cfg.Provider)
+}
+
+type jiraTracker struct{ forgeClient }
+
+func (t jiraTracker) IssueTitle(key string) (string, error) {
+	var issue struct {
+		Fields struct {
+			Summary string `json:"summary"`
+		} `json:"fields"`
+	}
+	if err := t.getJSON("/issue/"+url.PathEscape(key)+"?fields=summary", &issue); err != nil {
+		return "", err
+	}
+	return issue.Fields.Summary, nil
+}
+
+type linearTracker struct{ forgeClient }
+
+func (t linearTracker) IssueTitle(key string) (string, error) {
+	request := map[string]interface{}{
+		"query":     `query($id: String!) { issue(id: $id) { title } }`,
+		"variables": map[string]string{"id": key},
+	}
+	var response struct {
+		Data struct {
+			Issue *struct {
+				Title string `json:"title"`
+			} `json:"issue"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := t.postJSON("/graphql", request, &response); err != nil {
+		return "", err
+	}
+	if len(response.Errors) > 0 {
+		return "", fmt.Errorf("linear: %s", response.Errors[0].Message)
+	}
+	if response.Data.Issue == nil {
+		return "", fmt.Errorf("issue %s not found", key)
+	}
+	return response.Data.Issue.Title, nil
+}
+
+type githubIssueTracker struct{ forgeClient }
+
+func (t githubIssueTracker) IssueTitle(key string) (string, error) {
+	var issue struct {
+		Title string `json:"title"`
+	}
+	if err := t.getJSON("/repos/"+t.repo+"/issues/"+key, &issue); err != nil {
+		return "", err
+	}
+	return issue.Title, nil
+}
+
+// loadIssueTracker sets up the configured tracker for the TUI. Like a
+// broken forge config, a broken tracker config doesn't stop wtree: the
+// status line reports it and issue keys are taken as plain branch names.
+func (m *model) loadIssueTracker(cfg TrackerConfig) {
+	tracker, err := newIssueTracker(m.repo, cfg)
+	if err != nil {
+		m.tracker = nil
+		m.statusMessage = fmt.Sprintf("%s Issue tracker disabled: %v", markers.Error, err)
+		return
+	}
+	m.tracker = tracker
+	if tracker != nil {
+		m.newBranchInput.Placeholder = "Enter branch name or issue key..."
+	}
+}
+
+// issueBranchProposedMsg carries the branch name proposed for an issue.
+type issueBranchProposedMsg struct {
+	key    string
+	title  string
+	branch string
+}
+
+// linkIssueCmd records the issue a new worktree was created for and
+// refreshes the list.
+func linkIssueCmd(repo, path, issue string) tea.Cmd {
+	return func() tea.Msg {
+		if issue != "" {
+			if err := editWorktreeMeta(repo, path, func(meta *WorktreeMeta) { meta.Issue = issue }); err != nil {
+				return err
+			}
+		}
+		return getWorktreesCmd(repo)()
+	}
+}
+
+func lookupIssueBranchCmd(tracker IssueTracker, cfg TrackerConfig, key string) tea.Cmd {
+	return func() tea.Msg {
+		title, err := tracker.IssueTitle(key)
+		if err != nil {
+			return fmt.Errorf("could not look up %s: %w", key, err)
+		}
+		return issueBranchProposedMsg{key: key, title: title, branch: cfg.branchName(key, title)}
+	}
+}
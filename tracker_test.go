@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"Fix login: crash on Safari!": "fix-login-crash-on-safari",
+		"  Leading & trailing  ":      "leading-trailing",
+		"A very long issue title that keeps going well past the limit of fifty": "a-very-long-issue-title-that-keeps-going-well",
+	}
+	for input, expected := range tests {
+		if slug := slugify(input); slug != expected {
+			t.Errorf("slugify(%q) = %q, expected %q", input, slug, expected)
+		}
+	}
+}
+
+func TestTrackerIssueKeyAndTemplate(t *testing.T) {
+	jira := TrackerConfig{Provider: "jira", Projects: []string{"ABC"}}
+	if key, ok := jira.issueKey("abc-123"); !ok || key != "ABC-123" {
+		t.Errorf("Expected ABC-123, got %q %v", key, ok)
+	}
+	for _, name := range []string{"feature/login", "hotfix-42", "release-2"} {
+		if _, ok := jira.issueKey(name); ok {
+			t.Errorf("Expected %q not to be an issue key", name)
+		}
+	}
+	if key, ok := (TrackerConfig{Provider: "github"}).issueKey("#42"); !ok || key != "42" {
+		t.Errorf("Expected 42, got %q %v", key, ok)
+	}
+
+	if name := jira.branchName("ABC-123", "Short title"); name != "ABC-123-short-title" {
+		t.Errorf("Unexpected default branch name %q", name)
+	}
+	custom := TrackerConfig{Template: "feature/{key}/{title}"}
+	if name := custom.branchName("ABC-1", "Do it"); name != "feature/ABC-1/do-it" {
+		t.Errorf("Unexpected templated branch name %q", name)
+	}
+}
+
+func TestIssueTrackers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/api/2/issue/ABC-123":
+			if r.Header.Get("Authorization") != "Basic bWVAZXhhbXBsZS5jb206c2VjcmV0" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"fields": map[string]string{"summary": "Jira title"}})
+		case "/graphql":
+			var request struct {
+				Variables map[string]string `json:"variables"`
+			}
+			json.NewDecoder(r.Body).Decode(&request)
+			if request.Variables["id"] != "ENG-7" {
+				json.NewEncoder(w).Encode(map[string]interface{}{"errors": []map[string]string{{"message": "not found"}}})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"issue": map[string]string{"title": "Linear title"}}})
+		case "/repos/acme/app/issues/42":
+			json.NewEncoder(w).Encode(map[string]string{"title": "GitHub title"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("TEST_TRACKER_TOKEN", "secret")
+
+	tests := []struct {
+		cfg      TrackerConfig
+		key      string
+		expected string
+	}{
+		{TrackerConfig{Provider: "jira", BaseURL: server.URL, User: "me@example.com", TokenEnv: "TEST_TRACKER_TOKEN"}, "ABC-123", "Jira title"},
+		{TrackerConfig{Provider: "linear", BaseURL: server.URL}, "ENG-7", "Linear title"},
+		{TrackerConfig{Provider: "github", BaseURL: server.URL, Repo: "acme/app"}, "42", "GitHub title"},
+	}
+	for _, test := range tests {
+		tracker, err := newIssueTracker(".", test.cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		title, err := tracker.IssueTitle(test.key)
+		if err != nil || title != test.expected {
+			t.Errorf("%s: expected %q, got %q (%v)", test.cfg.Provider, test.expected, title, err)
+		}
+	}
+
+	linear, _ := newIssueTracker(".", TrackerConfig{Provider: "linear", BaseURL: server.URL})
+	if _, err := linear.IssueTitle("ENG-8"); err == nil {
+		t.Error("Expected GraphQL errors to be reported")
+	}
+	if _, err := newIssueTracker(".", TrackerConfig{Provider: "jira"}); err == nil {
+		t.Error("Expected error for jira without base_url")
+	}
+	if _, err := newIssueTracker(".", TrackerConfig{Provider: "youtrack"}); err == nil {
+		t.Error("Expected error for unknown tracker")
+	}
+}
+
+type fakeTracker map[string]string
+
+func (f fakeTracker) IssueTitle(key string) (string, error) {
+	return f[key], nil
+}
+
+func TestNewBranchProposesIssueBranch(t *testing.T) {
+	m := initialModel()
+	m.config.Tracker = TrackerConfig{Provider: "jira", Projects: []string{"ABC"}}
+	m.tracker = fakeTracker{"ABC-123": "Add dark mode"}
+	m.view = "branches"
+	m.startNewBranch()
+	m.newBranchInput.SetValue("abc-123")
+
+	m, cmd := m.confirmNewBranch()
+	msg := cmd()
+	proposed, ok := msg.(issueBranchProposedMsg)
+	if !ok || proposed.branch != "ABC-123-add-dark-mode" {
+		t.Fatalf("Expected a proposed branch, got %#v", msg)
+	}
+
+	updated, _ := m.Update(proposed)
+	m = updated.(model)
+	if m.newBranchInput.Value() != "ABC-123-add-dark-mode" || !m.creatingBranch {
+		t.Errorf("Expected the proposal in the input, got %q", m.newBranchInput.Value())
+	}
+
+	// Confirming the proposed name creates the branch
+	_, cmd = m.confirmNewBranch()
+	if creating, ok := cmd().(newBranchCreatingMsg); !ok || creating.branchName != "ABC-123-add-dark-mode" {
+		t.Errorf("Expected branch creation, got %#v", cmd())
+	}
+}
+
+func TestNewBranchIssueKeyEscapes(t *testing.T) {
+	m := initialModel()
+	m.config.Tracker = TrackerConfig{Provider: "jira", Projects: []string{"ABC"}, Template: "{key}"}
+	m.tracker = fakeTracker{"ABC-123": "Add dark mode"}
+	m.view = "branches"
+	m.startNewBranch()
+
+	// The escape keeps a key-like name as the branch name
+	m.newBranchInput.SetValue(`\ABC-7`)
+	_, cmd := m.confirmNewBranch()
+	if creating, ok := cmd().(newBranchCreatingMsg); !ok || creating.branchName != "ABC-7" {
+		t.Errorf("Expected the escaped name to be created, got %#v", cmd())
+	}
+
+	// With a "{key}" template the proposal is the key, which isn't looked up again
+	m.newBranchInput.SetValue("ABC-123")
+	m, cmd = m.confirmNewBranch()
+	updated, _ := m.Update(cmd())
+	m = updated.(model)
+	if m.newBranchInput.Value() != "ABC-123" || m.newBranchError != "" {
+		t.Fatalf("Expected the key as the proposal, got %q (%s)", m.newBranchInput.Value(), m.newBranchError)
+	}
+	_, cmd = m.confirmNewBranch()
+	if creating, ok := cmd().(newBranchCreatingMsg); !ok || creating.branchName != "ABC-123" {
+		t.Errorf("Expected branch creation instead of another lookup, got %#v", cmd())
+	}
+}
+
+func TestBrokenTrackerConfigDisablesLookups(t *testing.T) {
+	m := initialModel()
+	m.config.Tracker = TrackerConfig{Provider: "jira", Projects: []string{"ABC"}}
+	m.loadIssueTracker(m.config.Tracker)
+	if m.tracker != nil || !strings.Contains(m.statusMessage, "base_url") {
+		t.Fatalf("Expected the tracker disabled with the config error, got %v, %q", m.tracker, m.statusMessage)
+	}
+
+	// Issue keys are taken as branch names instead of being looked up
+	m.view = "branches"
+	m.startNewBranch()
+	m.newBranchInput.SetValue("ABC-123")
+	_, cmd := m.confirmNewBranch()
+	if creating, ok := cmd().(newBranchCreatingMsg); !ok || creating.branchName != "ABC-123" {
+		t.Errorf("Expected branch creation, got %#v", cmd())
+	}
+}